@@ -0,0 +1,274 @@
+// Package accountsmock provides test doubles of accounts.Service: Service, a mock
+// with stub functions that records calls, and Store, a stateful in-memory implementation
+// with error semantics of the real client.
+//
+//	mock := &accountsmock.Service{
+//		FetchFunc: func(ctx context.Context, id string) (*accounts.FetchSuccess, error) {
+//			return nil, &accounts.AccountNotFoundError{ID: id}
+//		},
+//	}
+//	...
+//	mock.AssertCalled(t, accountsmock.MethodFetch, id)
+package accountsmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/althink/form3/accounts"
+)
+
+// Names of accounts.Service methods.
+const (
+	MethodCreate   = "Create"
+	MethodFetch    = "Fetch"
+	MethodUpdate   = "Update"
+	MethodDelete   = "Delete"
+	MethodList     = "List"
+	MethodListNext = "ListNext"
+)
+
+// ErrNotStubbed is returned by methods of Service without a stub function.
+var ErrNotStubbed = errors.New("method not stubbed")
+
+// Any matches any argument in assertions.
+var Any = anyArg{}
+
+type anyArg struct{}
+
+// Call is a recorded call of a Service method. Args don't include the context.
+type Call struct {
+	Method string
+	Ctx    context.Context
+	Args   []interface{}
+}
+
+// TestingT is a subset of testing.T used by assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Service is a mock of accounts.Service. Methods call the stub functions, which can be changed
+// between calls, and record their calls. Methods without a stub return ErrNotStubbed.
+// It's safe for concurrent use, as long as stubs are not changed concurrently with calls.
+type Service struct {
+	CreateFunc   func(ctx context.Context, account *accounts.Data) (*accounts.CreateSuccess, error)
+	FetchFunc    func(ctx context.Context, id string) (*accounts.FetchSuccess, error)
+	UpdateFunc   func(ctx context.Context, id string, version int64, patch *accounts.Attributes) (*accounts.UpdateSuccess, error)
+	DeleteFunc   func(ctx context.Context, id string, version int64) error
+	ListFunc     func(ctx context.Context, opts accounts.ListOptions) (*accounts.ListSuccess, error)
+	ListNextFunc func(ctx context.Context, page *accounts.ListSuccess) (*accounts.ListSuccess, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ accounts.Service = (*Service)(nil)
+
+// NewInMemory returns a Service with all methods stubbed by a new Store.
+// Single stubs can be replaced, e.g. to make a method fail.
+func NewInMemory() *Service {
+	return Wrap(NewStore())
+}
+
+// Wrap returns a Service with all methods stubbed by given service, recording its calls.
+func Wrap(s accounts.Service) *Service {
+	return &Service{
+		CreateFunc:   s.Create,
+		FetchFunc:    s.Fetch,
+		UpdateFunc:   s.Update,
+		DeleteFunc:   s.Delete,
+		ListFunc:     s.List,
+		ListNextFunc: s.ListNext,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, account *accounts.Data) (*accounts.CreateSuccess, error) {
+	s.record(ctx, MethodCreate, account)
+	if s.CreateFunc == nil {
+		return nil, notStubbed(MethodCreate)
+	}
+	return s.CreateFunc(ctx, account)
+}
+
+func (s *Service) Fetch(ctx context.Context, id string) (*accounts.FetchSuccess, error) {
+	s.record(ctx, MethodFetch, id)
+	if s.FetchFunc == nil {
+		return nil, notStubbed(MethodFetch)
+	}
+	return s.FetchFunc(ctx, id)
+}
+
+func (s *Service) Update(ctx context.Context, id string, version int64, patch *accounts.Attributes) (*accounts.UpdateSuccess, error) {
+	s.record(ctx, MethodUpdate, id, version, patch)
+	if s.UpdateFunc == nil {
+		return nil, notStubbed(MethodUpdate)
+	}
+	return s.UpdateFunc(ctx, id, version, patch)
+}
+
+func (s *Service) Delete(ctx context.Context, id string, version int64) error {
+	s.record(ctx, MethodDelete, id, version)
+	if s.DeleteFunc == nil {
+		return notStubbed(MethodDelete)
+	}
+	return s.DeleteFunc(ctx, id, version)
+}
+
+func (s *Service) List(ctx context.Context, opts accounts.ListOptions) (*accounts.ListSuccess, error) {
+	s.record(ctx, MethodList, opts)
+	if s.ListFunc == nil {
+		return nil, notStubbed(MethodList)
+	}
+	return s.ListFunc(ctx, opts)
+}
+
+func (s *Service) ListNext(ctx context.Context, page *accounts.ListSuccess) (*accounts.ListSuccess, error) {
+	s.record(ctx, MethodListNext, page)
+	if s.ListNextFunc == nil {
+		return nil, notStubbed(MethodListNext)
+	}
+	return s.ListNextFunc(ctx, page)
+}
+
+func (s *Service) record(ctx context.Context, method string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, Call{Method: method, Ctx: ctx, Args: args})
+}
+
+func notStubbed(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotStubbed, method)
+}
+
+// Calls returns all recorded calls in order.
+func (s *Service) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsOf returns recorded calls of given method in order.
+func (s *Service) CallsOf(method string) []Call {
+	var res []Call
+	for _, c := range s.Calls() {
+		if c.Method == method {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// CallCount returns the number of calls of given method.
+func (s *Service) CallCount(method string) int {
+	return len(s.CallsOf(method))
+}
+
+// Reset forgets recorded calls. Stubs are kept.
+func (s *Service) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// AssertCalled checks that given method was called with given arguments, without the context.
+// Arguments are compared deeply, Any matches any argument.
+func (s *Service) AssertCalled(t TestingT, method string, args ...interface{}) bool {
+	t.Helper()
+	calls := s.CallsOf(method)
+	for _, c := range calls {
+		if argsMatch(args, c.Args) {
+			return true
+		}
+	}
+	if len(calls) == 0 {
+		t.Errorf("Expected %s to be called with %s, but it wasn't called", method, format(args))
+		return false
+	}
+	t.Errorf("Expected %s to be called with %s, but it was called with:\n%s", method, format(args), formatCalls(calls))
+	return false
+}
+
+// AssertNotCalled checks that given method wasn't called.
+func (s *Service) AssertNotCalled(t TestingT, method string) bool {
+	t.Helper()
+	if calls := s.CallsOf(method); len(calls) > 0 {
+		t.Errorf("Expected %s not to be called, but it was called with:\n%s", method, formatCalls(calls))
+		return false
+	}
+	return true
+}
+
+// AssertCallCount checks that given method was called given number of times.
+func (s *Service) AssertCallCount(t TestingT, method string, count int) bool {
+	t.Helper()
+	if n := s.CallCount(method); n != count {
+		t.Errorf("Expected %s to be called %d times, but it was called %d times", method, count, n)
+		return false
+	}
+	return true
+}
+
+// AssertCallOrder checks that given methods were called in given order. Other calls may happen
+// before, between and after them.
+func (s *Service) AssertCallOrder(t TestingT, methods ...string) bool {
+	t.Helper()
+	calls := s.Calls()
+	i := 0
+	for _, c := range calls {
+		if i < len(methods) && c.Method == methods[i] {
+			i++
+		}
+	}
+	if i < len(methods) {
+		var actual []string
+		for _, c := range calls {
+			actual = append(actual, c.Method)
+		}
+		t.Errorf("Expected calls in order %v, but calls were %v", methods, actual)
+		return false
+	}
+	return true
+}
+
+func argsMatch(expected, actual []interface{}) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if expected[i] == Any {
+			continue
+		}
+		if !reflect.DeepEqual(expected[i], actual[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func format(args []interface{}) string {
+	s := "("
+	for i, a := range args {
+		if i > 0 {
+			s += ", "
+		}
+		if a == Any {
+			s += "Any"
+		} else {
+			s += fmt.Sprintf("%+v", a)
+		}
+	}
+	return s + ")"
+}
+
+func formatCalls(calls []Call) string {
+	s := ""
+	for _, c := range calls {
+		s += fmt.Sprintf("\t%s%s\n", c.Method, format(c.Args))
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package accountsmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/althink/form3/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Mock_StubbedCalls(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fetched := &accounts.FetchSuccess{Data: accounts.New("id-1", "org", nil)}
+	mock := &Service{
+		FetchFunc: func(ctx context.Context, id string) (*accounts.FetchSuccess, error) {
+			return fetched, nil
+		},
+		DeleteFunc: func(ctx context.Context, id string, version int64) error {
+			return &accounts.InvalidVersionError{Ver: version}
+		},
+	}
+
+	// when
+	res, err := mock.Fetch(ctx, "id-1")
+	delErr := mock.Delete(ctx, "id-1", 3)
+	_, listErr := mock.List(ctx, accounts.ListOptions{PageSize: 10})
+
+	// then
+	require.Empty(t, err)
+	require.Same(t, fetched, res)
+	require.True(t, errors.Is(delErr, accounts.ErrConflict))
+	require.True(t, errors.Is(listErr, ErrNotStubbed), "Invalid error: %v", listErr)
+	require.Equal(t, []Call{
+		{Method: MethodFetch, Ctx: ctx, Args: []interface{}{"id-1"}},
+		{Method: MethodDelete, Ctx: ctx, Args: []interface{}{"id-1", int64(3)}},
+		{Method: MethodList, Ctx: ctx, Args: []interface{}{accounts.ListOptions{PageSize: 10}}},
+	}, mock.Calls())
+	require.Equal(t, 1, mock.CallCount(MethodDelete))
+	require.Len(t, mock.CallsOf(MethodCreate), 0)
+}
+
+func Test_Mock_Assertions(t *testing.T) {
+	// given
+	ctx := context.Background()
+	mock := NewInMemory()
+	acc := accounts.New("id-1", "org", &accounts.Attributes{Country: "GB"})
+	mock.Create(ctx, acc)
+	mock.Fetch(ctx, "id-1")
+	mock.Delete(ctx, "id-1", 0)
+
+	// expect
+	rec := &recordingT{}
+	require.True(t, mock.AssertCalled(rec, MethodCreate, acc))
+	require.True(t, mock.AssertCalled(rec, MethodDelete, "id-1", Any))
+	require.True(t, mock.AssertNotCalled(rec, MethodUpdate))
+	require.True(t, mock.AssertCallCount(rec, MethodFetch, 1))
+	require.True(t, mock.AssertCallOrder(rec, MethodCreate, MethodDelete))
+	require.Empty(t, rec.errors)
+
+	require.False(t, mock.AssertCalled(rec, MethodFetch, "id-2"))
+	require.False(t, mock.AssertCalled(rec, MethodList, Any))
+	require.False(t, mock.AssertNotCalled(rec, MethodDelete))
+	require.False(t, mock.AssertCallCount(rec, MethodCreate, 2))
+	require.False(t, mock.AssertCallOrder(rec, MethodDelete, MethodCreate))
+	require.Equal(t, []string{
+		"Expected Fetch to be called with (id-2), but it was called with:\n\tFetch(id-1)\n",
+		"Expected List to be called with (Any), but it wasn't called",
+		"Expected Delete not to be called, but it was called with:\n\tDelete(id-1, 0)\n",
+		"Expected Create to be called 2 times, but it was called 1 times",
+		"Expected calls in order [Delete Create], but calls were [Create Fetch Delete]",
+	}, rec.errors)
+
+	// when
+	mock.Reset()
+
+	// then
+	require.Empty(t, mock.Calls())
+}
+
+func Test_Mock_OverrideInMemoryStub(t *testing.T) {
+	// given
+	ctx := context.Background()
+	mock := NewInMemory()
+	mock.CreateFunc = func(ctx context.Context, account *accounts.Data) (*accounts.CreateSuccess, error) {
+		return nil, &accounts.RateLimitedError{}
+	}
+
+	// when
+	_, err := mock.Create(ctx, accounts.New("id-1", "org", nil))
+	_, fetchErr := mock.Fetch(ctx, "id-1")
+
+	// then
+	require.True(t, errors.Is(err, accounts.ErrRateLimited))
+	require.IsType(t, &accounts.AccountNotFoundError{}, fetchErr)
+}
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
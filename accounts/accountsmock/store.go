@@ -0,0 +1,371 @@
+package accountsmock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/althink/form3/accounts"
+)
+
+// Default page size of List, like the one of Form3 API.
+const DefaultPageSize = 100
+
+const accountsPath = "/v1/organisation/accounts"
+
+// Store is an in-memory implementation of accounts.Service. Accounts are kept in a map and
+// returned as copies. Versions start at 0 and are incremented by Update. Errors mirror the real client:
+// AccountAlreadyExistsError for duplicate IDs, AccountNotFoundError for unknown IDs and
+// InvalidVersionError for version mismatches, all with the status code of the API response.
+// It's safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	accounts map[string]*accounts.Data
+	order    []string
+}
+
+var _ accounts.Service = (*Store)(nil)
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{accounts: map[string]*accounts.Data{}}
+}
+
+// Add stores a copy of the account as it is, replacing any with the same ID.
+// Missing type and version are set.
+func (s *Store) Add(account *accounts.Data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := clone(account)
+	if a.Type == "" {
+		a.Type = accounts.Type
+	}
+	if a.Version == nil {
+		a.Version = new(int64)
+	}
+	if _, ok := s.accounts[a.ID]; !ok {
+		s.order = append(s.order, a.ID)
+	}
+	s.accounts[a.ID] = a
+}
+
+// Account returns a copy of stored account with given ID.
+func (s *Store) Account(id string) (*accounts.Data, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.accounts[id]
+	if !ok {
+		return nil, false
+	}
+	return clone(a), true
+}
+
+// Accounts returns copies of all stored accounts in order of creation.
+func (s *Store) Accounts() []*accounts.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]*accounts.Data, 0, len(s.order))
+	for _, id := range s.order {
+		res = append(res, clone(s.accounts[id]))
+	}
+	return res
+}
+
+func (s *Store) Create(ctx context.Context, account *accounts.Data) (*accounts.CreateSuccess, error) {
+	if account == nil {
+		return nil, invalidData(http.MethodPost, accountsPath, "data in body is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[account.ID]; ok {
+		return nil, &accounts.AccountAlreadyExistsError{
+			APIError: apiError(http.StatusConflict, http.MethodPost, accountsPath, "Account cannot be created as it violates a duplicate constraint"),
+			ID:       account.ID,
+		}
+	}
+	a := clone(account)
+	a.Version = new(int64)
+	s.accounts[a.ID] = a
+	s.order = append(s.order, a.ID)
+	return &accounts.CreateSuccess{Data: clone(a), Links: selfLink(a.ID)}, nil
+}
+
+func (s *Store) Fetch(ctx context.Context, id string) (*accounts.FetchSuccess, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.find(http.MethodGet, id)
+	if err != nil {
+		return nil, err
+	}
+	return &accounts.FetchSuccess{Data: clone(a), Links: selfLink(id)}, nil
+}
+
+func (s *Store) Update(ctx context.Context, id string, version int64, patch *accounts.Attributes) (*accounts.UpdateSuccess, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.find(http.MethodPatch, id)
+	if err != nil {
+		return nil, err
+	}
+	if *a.Version != version {
+		return nil, invalidVersion(http.MethodPatch, id, version)
+	}
+	if err := merge(a, patch); err != nil {
+		return nil, invalidData(http.MethodPatch, accountPath(id), err.Error())
+	}
+	*a.Version++
+	return &accounts.UpdateSuccess{Data: clone(a), Links: selfLink(id)}, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.find(http.MethodDelete, id)
+	if err != nil {
+		return err
+	}
+	if *a.Version != version {
+		return invalidVersion(http.MethodDelete, id, version)
+	}
+	delete(s.accounts, id)
+	for i, o := range s.order {
+		if o == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// List returns a page of accounts matching the filter, in order of creation,
+// with links that can be followed with ListNext. Like in the API, filters
+// with comma separated values match any of them.
+func (s *Store) List(ctx context.Context, opts accounts.ListOptions) (*accounts.ListSuccess, error) {
+	if opts.PageNumber < 0 || opts.PageSize < 0 {
+		return nil, invalidData(http.MethodGet, accountsPath, "invalid page parameters")
+	}
+	size := opts.PageSize
+	if size == 0 {
+		size = DefaultPageSize
+	}
+
+	s.mu.Lock()
+	var matching []*accounts.Data
+	for _, id := range s.order {
+		if a := s.accounts[id]; matches(a, opts.Filter) {
+			matching = append(matching, clone(a))
+		}
+	}
+	s.mu.Unlock()
+
+	last := 0
+	if len(matching) > 0 {
+		last = (len(matching) - 1) / size
+	}
+	page := []*accounts.Data{}
+	if from := opts.PageNumber * size; from < len(matching) {
+		to := from + size
+		if to > len(matching) {
+			to = len(matching)
+		}
+		page = matching[from:to]
+	}
+
+	link := func(n int) *string {
+		l := listLink(opts.Filter, n, size)
+		return &l
+	}
+	links := &accounts.ListLinks{First: link(0), Last: link(last)}
+	links.Self = link(opts.PageNumber)
+	if opts.PageNumber < last {
+		links.Next = link(opts.PageNumber + 1)
+	}
+	if opts.PageNumber > 0 {
+		links.Prev = link(opts.PageNumber - 1)
+	}
+	return &accounts.ListSuccess{Data: page, Links: links}, nil
+}
+
+// ListNext lists the page pointed by the Next link of given page.
+func (s *Store) ListNext(ctx context.Context, page *accounts.ListSuccess) (*accounts.ListSuccess, error) {
+	if !page.HasNext() {
+		return nil, nil
+	}
+	u, err := url.Parse(*page.Links.Next)
+	if err != nil {
+		return nil, invalidData(http.MethodGet, *page.Links.Next, err.Error())
+	}
+	opts, err := ListOptionsFromQuery(u.Query())
+	if err != nil {
+		return nil, invalidData(http.MethodGet, *page.Links.Next, err.Error())
+	}
+	return s.List(ctx, opts)
+}
+
+func (s *Store) find(method, id string) (*accounts.Data, error) {
+	a, ok := s.accounts[id]
+	if !ok {
+		return nil, &accounts.AccountNotFoundError{
+			APIError: apiError(http.StatusNotFound, method, accountPath(id), fmt.Sprintf("record %s does not exist", id)),
+			ID:       id,
+		}
+	}
+	return a, nil
+}
+
+func invalidVersion(method, id string, version int64) error {
+	return &accounts.InvalidVersionError{
+		APIError: apiError(http.StatusConflict, method, accountPath(id), "invalid version"),
+		Ver:      version,
+	}
+}
+
+func invalidData(method, path, msg string) error {
+	return &accounts.InvalidDataError{APIError: apiError(http.StatusBadRequest, method, path, msg)}
+}
+
+func apiError(status int, method, path, msg string) accounts.APIError {
+	return accounts.APIError{StatusCode: status, Method: method, URL: path, Msg: msg}
+}
+
+func accountPath(id string) string {
+	return fmt.Sprintf("%s/%s", accountsPath, id)
+}
+
+func selfLink(id string) *accounts.Links {
+	self := accountPath(id)
+	return &accounts.Links{Self: &self}
+}
+
+func filters(f accounts.ListFilter) map[string]string {
+	return map[string]string{
+		"bank_id":        f.BankID,
+		"bank_id_code":   string(f.BankIDCode),
+		"account_number": f.AccountNumber,
+		"iban":           f.Iban,
+		"country":        f.Country,
+		"customer_id":    f.CustomerID,
+	}
+}
+
+func matches(a *accounts.Data, f accounts.ListFilter) bool {
+	attrs := a.Attributes
+	if attrs == nil {
+		attrs = &accounts.Attributes{}
+	}
+	actual := filters(accounts.ListFilter{
+		BankID:        attrs.BankID,
+		BankIDCode:    attrs.BankIDCode,
+		AccountNumber: attrs.AccountNumber,
+		Iban:          attrs.Iban,
+		Country:       attrs.Country,
+		CustomerID:    attrs.CustomerID,
+	})
+	for name, value := range filters(f) {
+		if value != "" && !matchesAny(value, actual[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(values, actual string) bool {
+	for _, v := range strings.Split(values, ",") {
+		if v == actual {
+			return true
+		}
+	}
+	return false
+}
+
+func listLink(f accounts.ListFilter, number, size int) string {
+	q := url.Values{}
+	for name, value := range filters(f) {
+		if value != "" {
+			q.Set(fmt.Sprintf("filter[%s]", name), value)
+		}
+	}
+	q.Set("page[number]", strconv.Itoa(number))
+	q.Set("page[size]", strconv.Itoa(size))
+	return accountsPath + "?" + q.Encode()
+}
+
+// ListOptionsFromQuery parses page[number], page[size] and filter[name] parameters of list requests,
+// like the ones of links returned by List. Missing parameters are left empty, unknown filters are ignored.
+func ListOptionsFromQuery(q url.Values) (accounts.ListOptions, error) {
+	page := func(name string) (int, error) {
+		v := q.Get(name)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid %s", name)
+		}
+		return n, nil
+	}
+	number, err := page("page[number]")
+	if err != nil {
+		return accounts.ListOptions{}, err
+	}
+	size, err := page("page[size]")
+	if err != nil {
+		return accounts.ListOptions{}, err
+	}
+	f := func(name string) string {
+		return strings.Join(q[fmt.Sprintf("filter[%s]", name)], ",")
+	}
+	return accounts.ListOptions{
+		PageNumber: number,
+		PageSize:   size,
+		Filter: accounts.ListFilter{
+			BankID:        f("bank_id"),
+			BankIDCode:    accounts.BankIDCode(f("bank_id_code")),
+			AccountNumber: f("account_number"),
+			Iban:          f("iban"),
+			Country:       f("country"),
+			CustomerID:    f("customer_id"),
+		},
+	}, nil
+}
+
+// merge sets non-empty fields of the patch on the account, like Update of the API.
+func merge(a *accounts.Data, patch *accounts.Attributes) error {
+	if patch == nil {
+		return nil
+	}
+	current, err := json.Marshal(a.Attributes)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	m := map[string]json.RawMessage{}
+	if err := json.Unmarshal(current, &m); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(changes, &m); err != nil {
+		return err
+	}
+	merged, _ := json.Marshal(m)
+	attrs := &accounts.Attributes{}
+	if err := json.Unmarshal(merged, attrs); err != nil {
+		return err
+	}
+	a.Attributes = attrs
+	return nil
+}
+
+func clone(a *accounts.Data) *accounts.Data {
+	b, _ := json.Marshal(a)
+	c := &accounts.Data{}
+	json.Unmarshal(b, c)
+	return c
+}
@@ -0,0 +1,147 @@
+package accountsmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/althink/form3/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Store_CreateFetchUpdateDelete(t *testing.T) {
+	// given
+	ctx := context.Background()
+	s := NewStore()
+	acc := accounts.New("id-1", "org", &accounts.Attributes{Country: "GB", Name: []string{"Samantha Holder"}})
+
+	// when
+	created, err := s.Create(ctx, acc)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, int64(0), *created.Data.Version)
+	require.Equal(t, "/v1/organisation/accounts/id-1", *created.Links.Self)
+	require.Nil(t, acc.Version, "Requested account changed")
+
+	// when
+	updated, err := s.Update(ctx, "id-1", 0, &accounts.Attributes{BankID: "400300"})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, int64(1), *updated.Data.Version)
+	require.Equal(t, "GB", updated.Data.Attributes.Country)
+	require.Equal(t, "400300", updated.Data.Attributes.BankID)
+
+	// when
+	fetched, err := s.Fetch(ctx, "id-1")
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, updated.Data, fetched.Data)
+
+	// when
+	fetched.Data.Attributes.Country = "PL"
+	err = s.Delete(ctx, "id-1", 1)
+
+	// then
+	require.Empty(t, err)
+	require.Empty(t, s.Accounts())
+}
+
+func Test_Store_Errors(t *testing.T) {
+	// given
+	ctx := context.Background()
+	s := NewStore()
+	s.Add(accounts.New("id-1", "org", &accounts.Attributes{Country: "GB"}))
+
+	// when
+	_, createErr := s.Create(ctx, accounts.New("id-1", "org", nil))
+	_, fetchErr := s.Fetch(ctx, "id-2")
+	_, updateErr := s.Update(ctx, "id-1", 1, &accounts.Attributes{})
+	deleteErr := s.Delete(ctx, "id-1", 2)
+	deleteUnknownErr := s.Delete(ctx, "id-2", 0)
+
+	// then
+	require.IsType(t, &accounts.AccountAlreadyExistsError{}, createErr)
+	require.Equal(t, "id-1", createErr.(*accounts.AccountAlreadyExistsError).ID)
+	require.True(t, errors.Is(createErr, accounts.ErrConflict))
+
+	require.IsType(t, &accounts.AccountNotFoundError{}, fetchErr)
+	require.Equal(t, "id-2", fetchErr.(*accounts.AccountNotFoundError).ID)
+	require.Equal(t, 404, fetchErr.(*accounts.AccountNotFoundError).StatusCode)
+
+	require.IsType(t, &accounts.InvalidVersionError{}, updateErr)
+	require.Equal(t, int64(1), updateErr.(*accounts.InvalidVersionError).Ver)
+	require.IsType(t, &accounts.InvalidVersionError{}, deleteErr)
+	require.Equal(t, int64(2), deleteErr.(*accounts.InvalidVersionError).Ver)
+	require.IsType(t, &accounts.AccountNotFoundError{}, deleteUnknownErr)
+
+	stored, ok := s.Account("id-1")
+	require.True(t, ok)
+	require.Equal(t, int64(0), *stored.Version)
+}
+
+func Test_Store_ListPages(t *testing.T) {
+	// given
+	ctx := context.Background()
+	s := NewStore()
+	for i := 0; i < 5; i++ {
+		country := "GB"
+		if i%2 == 1 {
+			country = "PL"
+		}
+		s.Add(accounts.New(fmt.Sprintf("id-%d", i), "org", &accounts.Attributes{Country: country}))
+	}
+
+	// when
+	first, err := s.List(ctx, accounts.ListOptions{PageSize: 2, Filter: accounts.ListFilter{Country: "GB"}})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, []string{"id-0", "id-2"}, ids(first))
+	require.True(t, first.HasNext())
+
+	// when
+	second, err := s.ListNext(ctx, first)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, []string{"id-4"}, ids(second))
+	require.False(t, second.HasNext())
+
+	// when
+	none, err := s.ListNext(ctx, second)
+
+	// then
+	require.Empty(t, err)
+	require.Nil(t, none)
+}
+
+func ids(page *accounts.ListSuccess) []string {
+	var res []string
+	for _, a := range page.Data {
+		res = append(res, a.ID)
+	}
+	return res
+}
+
+func Test_Store_ListFilterAnyOf(t *testing.T) {
+	// given
+	ctx := context.Background()
+	s := NewStore()
+	for i, country := range []string{"GB", "PL", "DE"} {
+		s.Add(accounts.New(fmt.Sprintf("id-%d", i), "org", &accounts.Attributes{Country: country}))
+	}
+	opts, err := ListOptionsFromQuery(url.Values{"filter[country]": {"GB,DE"}, "page[size]": {"10"}})
+	require.Empty(t, err)
+
+	// when
+	list, err := s.List(ctx, opts)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, []string{"id-0", "id-2"}, ids(list))
+}
@@ -2,21 +2,31 @@ package accounts
 
 import (
 	"context"
+	"net/url"
 
+	"github.com/althink/form3/internal/transport"
 	"github.com/google/uuid"
 )
 
 // Accounts service interface
 // See https://api-docs.form3.tech/api.html#organisation-accounts for
 // more information about operations and fields.
+//
+// Errors returned for server responses embed APIError with the response details
+// and can be matched with errors.Is against ErrNotFound, ErrConflict, ErrRateLimited and ErrInvalidData.
 type Service interface {
 
 	// Create registers an existing bank account with Form3 or create a new one.
 	// The country attribute must be specified as a minimum.
 	// Depending on the country, other attributes such as bank_id and bic are mandatory.
+	// Idempotency-Key header is sent with the account ID, unless another key is set with WithIdempotencyKey.
 	//
+	// When client validation is enabled and account is invalid returns ValidationError
+	// When strict enums are enabled and account has an unknown enum value returns UnknownEnumError
 	// When data format is invalid returns InvalidDataError
-	// When account with given id already exists returns AccountAlreadyExistsError
+	// When account with given id already exists returns AccountAlreadyExistsError, or with idempotent create
+	// IdempotencyMismatchError when the existing account differs from the requested one
+	// When rate limited returns RateLimitedError
 	// When other http error status returned returns HttpStatusError
 	Create(ctx context.Context, account *Data) (*CreateSuccess, error)
 
@@ -24,16 +34,46 @@ type Service interface {
 	//
 	// When accound with given id does not exist returns AccountNotFoundError
 	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
 	// When other http error status returned returns HttpStatusError
 	Fetch(ctx context.Context, id string) (*FetchSuccess, error)
 
+	// Update changes attributes of an account with given id and version.
+	// Only non-empty fields of the patch are sent. The updated account
+	// with its new version is returned.
+	//
+	// When accound with given id does not exist returns AccountNotFoundError
+	// When version is invalid returns InvalidVersionError
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	Update(ctx context.Context, id string, version int64, patch *Attributes) (*UpdateSuccess, error)
+
 	// Delete deltes an account by given id and version.
 	//
 	// When accound with given id does not exist returns AccountNotFoundError
 	// When version is invalid returns InvalidVersionError
 	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
 	// When other http error status returned returns HttpStatusError
 	Delete(ctx context.Context, id string, version int64) error
+
+	// List returns a single page of accounts matching given options.
+	// Links of the returned page can be followed with ListNext.
+	//
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	List(ctx context.Context, opts ListOptions) (*ListSuccess, error)
+
+	// ListNext returns the page pointed by the Next link of given page.
+	// When there is no next page returns nil page and nil error.
+	//
+	// When the Next link has another scheme or host than the base URL returns ForeignURLError
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	ListNext(ctx context.Context, page *ListSuccess) (*ListSuccess, error)
 }
 
 type CreateSuccess struct {
@@ -46,6 +86,55 @@ type FetchSuccess struct {
 	Links *Links `json:"links"`
 }
 
+type UpdateSuccess struct {
+	Data  *Data  `json:"data"`
+	Links *Links `json:"links"`
+}
+
+type ListSuccess struct {
+	Data  []*Data    `json:"data"`
+	Links *ListLinks `json:"links"`
+}
+
+// HasNext reports whether there is a next page to fetch.
+func (l *ListSuccess) HasNext() bool {
+	return l != nil && l.Links.HasNext()
+}
+
+// Options of the accounts List operation.
+// Zero values are not sent, so the server defaults are used.
+type ListOptions struct {
+	// Number of the page to fetch, starting from 0.
+	PageNumber int
+
+	// Number of accounts on a single page.
+	PageSize int
+
+	Filter ListFilter
+}
+
+// Filters of the accounts List operation. Only accounts matching all
+// non-empty fields are returned.
+type ListFilter struct {
+	BankID        string
+	BankIDCode    BankIDCode
+	AccountNumber string
+	Iban          string
+	Country       string
+	CustomerID    string
+}
+
+func (o ListOptions) query() url.Values {
+	return transport.Query(transport.Page{Number: o.PageNumber, Size: o.PageSize}, map[string]string{
+		"bank_id":        o.Filter.BankID,
+		"bank_id_code":   string(o.Filter.BankIDCode),
+		"account_number": o.Filter.AccountNumber,
+		"iban":           o.Filter.Iban,
+		"country":        o.Filter.Country,
+		"customer_id":    o.Filter.CustomerID,
+	})
+}
+
 // Create new account object
 func New(id string, orgID string, attributes *Attributes) *Data {
 	return &Data{
@@ -89,11 +178,11 @@ type Attributes struct {
 
 	// Determines the qualifier code with which payments to the account are accepted.
 	// FPS: Must be a valid FPS Acceptance Qualifier if provided. Not supported for FPS Indirect via LHV.
-	AcceptanceQualifier *string `json:"acceptance_qualifier,omitempty"`
+	AcceptanceQualifier *AcceptanceQualifier `json:"acceptance_qualifier,omitempty"`
 
 	// Classification of account, only used for Confirmation of Payee (CoP).
 	// CoP: Can be either Personal or Business. Defaults to Personal.
-	AccountClassification *string `json:"account_classification,omitempty"`
+	AccountClassification *AccountClassification `json:"account_classification,omitempty"`
 
 	//Flag to indicate if the account has opted out of account matching, only used for Confirmation of Payee
 	// CoP: Set to true if the account has opted out of account matching. Defaults to false.
@@ -109,7 +198,7 @@ type Attributes struct {
 	BankID string `json:"bank_id,omitempty"`
 
 	// Identifies the type of bank ID being used, see documentation for allowed value for each country. Required value depends on country attribute.
-	BankIDCode string `json:"bank_id_code,omitempty"`
+	BankIDCode BankIDCode `json:"bank_id_code,omitempty"`
 
 	// ISO 4217 code used to identify the base currency of the account, e.g. 'GBP', 'EUR'
 	BaseCurrency string `json:"base_currency,omitempty"`
@@ -148,12 +237,12 @@ type Attributes struct {
 	// FPS: Can be pending, confirmed or closed. When this field is closed, status_reason must be provided.
 	// SEPA & FPS Indirect (LHV): Can be either pending, confirmed or failed.
 	// All other services: Can be pending or confirmed. pending is a virtual state and is immediately superseded by confirmed.
-	Status *string `json:"status,omitempty"`
+	Status *Status `json:"status,omitempty"`
 
 	// Provides additional account status information.
 	// FPS: Must be a valid reason code if status is closed, cannot be provided otherwise.
 	// Not supported for FPS Indirect via LHV.
-	StatusReason *string `json:"status_reason,omitempty"`
+	StatusReason *StatusReason `json:"status_reason,omitempty"`
 
 	// Flag to indicate if the account has been switched away from this organisation, only used for Confirmation of Payee (CoP)
 	// CoP: Set to true if the account has been switched using the Current Account Switching Service (CASS), false otherwise. Defaults to false.
@@ -165,7 +254,7 @@ type Attributes struct {
 
 	// Determines which validations are carried out on inbound payments to the account
 	// FPS: Only card is allowed. Not supported for FPS Indirect via LHV.
-	ValidationType *string `json:"validation_type,omitempty"`
+	ValidationType *ValidationType `json:"validation_type,omitempty"`
 }
 
 type UserDefinedData struct {
@@ -173,23 +262,6 @@ type UserDefinedData struct {
 	Value string `json:"value,omitempty"`
 }
 
-type Links struct {
-	// Link to this endpoint or resource. Required.
-	Self *string `json:"self"`
-}
-
-type ListLinks struct {
-	Links
+type Links = transport.Links
 
-	// Link to the first page of a paginated response.
-	First *string `json:"first,omitempty"`
-
-	// Link to the last page of a paginated response.
-	Last *string `json:"last,omitempty"`
-
-	// Link to the next page of a paginated response.
-	Next *string `json:"next,omitempty"`
-
-	// Link to the previous page of a paginated response.
-	Prev *string `json:"prev,omitempty"`
-}
+type ListLinks = transport.ListLinks
@@ -0,0 +1,118 @@
+package accounts
+
+import (
+	"strings"
+)
+
+// Branch code of the primary office, used when the BIC has only 8 characters.
+const PrimaryOfficeBranch = "XXX"
+
+// BIC is a SWIFT Business Identifier Code as defined by ISO 9362.
+type BIC struct {
+	// Institution (bank) code, 4 alphanumeric characters
+	Institution string
+
+	// ISO 3166-1 country code, 2 letters
+	Country string
+
+	// Location code, 2 alphanumeric characters
+	Location string
+
+	// Branch code, 3 alphanumeric characters. "XXX" for the primary office.
+	Branch string
+}
+
+// ParseBIC parses BIC in either 8 or 11 character format, e.g. 'NWBKGB22' or 'NWBKGB22XXX'.
+// Spaces are ignored and letters are converted to upper case.
+// 8 character BICs get the "XXX" primary office branch code.
+func ParseBIC(s string) (BIC, error) {
+	norm := strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	if len(norm) != 8 && len(norm) != 11 {
+		return BIC{}, &InvalidBICError{BIC: s, Reason: "must have 8 or 11 characters"}
+	}
+	b := BIC{
+		Institution: norm[0:4],
+		Country:     norm[4:6],
+		Location:    norm[6:8],
+		Branch:      PrimaryOfficeBranch,
+	}
+	if len(norm) == 11 {
+		b.Branch = norm[8:11]
+	}
+
+	if !isAlnum(b.Institution) {
+		return BIC{}, &InvalidBICError{BIC: s, Reason: "institution code must be alphanumeric"}
+	}
+	if !isAlpha(b.Country) {
+		return BIC{}, &InvalidBICError{BIC: s, Reason: "country code must have letters only"}
+	}
+	if !isAlnum(b.Location) {
+		return BIC{}, &InvalidBICError{BIC: s, Reason: "location code must be alphanumeric"}
+	}
+	if !isAlnum(b.Branch) {
+		return BIC{}, &InvalidBICError{BIC: s, Reason: "branch code must be alphanumeric"}
+	}
+	return b, nil
+}
+
+// NormalizeBIC parses the BIC and returns it in 11 character format.
+func NormalizeBIC(s string) (string, error) {
+	b, err := ParseBIC(s)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// String returns the BIC in 11 character format.
+func (b BIC) String() string {
+	return b.Institution + b.Country + b.Location + b.Branch
+}
+
+// Short returns the 8 character BIC of the institution, without the branch code.
+func (b BIC) Short() string {
+	return b.Institution + b.Country + b.Location
+}
+
+// IsPrimaryOffice reports whether the BIC identifies the primary office of the institution.
+func (b BIC) IsPrimaryOffice() bool {
+	return b.Branch == PrimaryOfficeBranch
+}
+
+// IsTest reports whether the BIC is a test BIC, that is it has '0' as the second location character.
+func (b BIC) IsTest() bool {
+	return b.Location[1] == '0'
+}
+
+// Territories that use BICs of another country.
+var bicCountryAliases = map[string]string{
+	"GG": "GB",
+	"IM": "GB",
+	"JE": "GB",
+}
+
+// MatchesCountry reports whether the BIC can be used for an account domiciled in given country.
+func (b BIC) MatchesCountry(country string) bool {
+	if alias, ok := bicCountryAliases[country]; ok && alias == b.Country {
+		return true
+	}
+	return b.Country == country
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9') && !(s[i] >= 'A' && s[i] <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= 'A' && s[i] <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Accounts_ParseBICSuccess(t *testing.T) {
+	tests := map[string]BIC{
+		"NWBKGB22":    {Institution: "NWBK", Country: "GB", Location: "22", Branch: "XXX"},
+		"nwbkgb22":    {Institution: "NWBK", Country: "GB", Location: "22", Branch: "XXX"},
+		"DEUTDEFF500": {Institution: "DEUT", Country: "DE", Location: "FF", Branch: "500"},
+		"BNPA FR PP":  {Institution: "BNPA", Country: "FR", Location: "PP", Branch: "XXX"},
+		"ABNANL2AXXX": {Institution: "ABNA", Country: "NL", Location: "2A", Branch: "XXX"},
+	}
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			// when
+			b, err := ParseBIC(s)
+
+			// then
+			require.Empty(t, err)
+			require.Equal(t, expected, b)
+			require.Equal(t, expected.Institution+expected.Country+expected.Location+expected.Branch, b.String())
+		})
+	}
+}
+
+func Test_Accounts_ParseBICFailed(t *testing.T) {
+	tests := []string{
+		"",
+		"NWBKGB2",
+		"NWBKGB22XX",
+		"NWBKGB22XXXX",
+		"NW-KGB22",
+		"NWBK1222",
+		"NWBKGB2_",
+		"NWBKGB22X_X",
+	}
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			// when
+			_, err := ParseBIC(s)
+
+			// then
+			require.IsType(t, &InvalidBICError{}, err, "Invalid error type")
+			require.Equal(t, s, err.(*InvalidBICError).BIC)
+		})
+	}
+}
+
+func Test_Accounts_NormalizeBIC(t *testing.T) {
+	// when
+	norm, err := NormalizeBIC("nwbkgb22")
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "NWBKGB22XXX", norm)
+}
+
+func Test_Accounts_BICProperties(t *testing.T) {
+	// given
+	b, err := ParseBIC("NWBKGB20")
+	require.Empty(t, err)
+
+	// then
+	require.True(t, b.IsPrimaryOffice())
+	require.True(t, b.IsTest())
+	require.Equal(t, "NWBKGB20", b.Short())
+	require.True(t, b.MatchesCountry("GB"))
+	require.True(t, b.MatchesCountry("JE"))
+	require.False(t, b.MatchesCountry("IE"))
+}
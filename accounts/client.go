@@ -1,139 +1,253 @@
 package accounts
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"time"
+
+	"github.com/althink/form3/internal/transport"
 )
 
 const accountsBasePath = "organisation/accounts"
 
-func NewClient(c *http.Client, baseURL url.URL) Service {
-	return &httpClient{httpClient: c, baseURL: baseURL}
+// ClientOption configures the accounts client. Transport settings, like retries
+// or middlewares, are set with form3.NewClient options.
+type ClientOption func(transport.Configurable)
+
+func option(f func(c *httpClient)) ClientOption {
+	return func(c transport.Configurable) {
+		f(c.(*httpClient))
+	}
+}
+
+// WithValidation makes Create validate the account with Validate before sending it,
+// so invalid accounts fail with ValidationError without calling the server.
+func WithValidation() ClientOption {
+	return option(func(c *httpClient) {
+		c.validate = true
+	})
+}
+
+// WithStrictEnums makes the client reject unknown values of enumerated attributes,
+// like Status or BankIDCode, with UnknownEnumError. Accounts passed to Create and Update are checked
+// before anything is sent, and fetched or listed accounts when they are received. Accounts returned
+// by Create and Update aren't checked, because the server has already written them.
+// By default unknown values are passed through.
+func WithStrictEnums() ClientOption {
+	return option(func(c *httpClient) {
+		c.strictEnums = true
+	})
+}
+
+func NewClient(c *http.Client, baseURL url.URL, opts ...ClientOption) Service {
+	client := &httpClient{}
+	for _, o := range opts {
+		o(client)
+	}
+	client.t = transport.New(c, baseURL, client.transportOpts...)
+	return client
+}
+
+func (c *httpClient) AddOptions(opts ...transport.Option) {
+	c.transportOpts = append(c.transportOpts, opts...)
 }
 
 type httpClient struct {
-	httpClient *http.Client
-	baseURL    url.URL
+	t             *transport.Client
+	transportOpts []transport.Option
+	validate      bool
+	strictEnums   bool
+
+	idempotentCreate bool
 }
 
 func (c *httpClient) Create(ctx context.Context, account *Data) (*CreateSuccess, error) {
-	req, err := c.newRequest(ctx, "POST", accountsBasePath, createRequest{Data: account})
+	if c.validate {
+		if err := Validate(account); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkEnums(account); err != nil {
+		return nil, err
+	}
+	req, err := c.t.NewRequest(ctx, "POST", accountsBasePath, transport.Envelope{Data: account})
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set(IdempotencyKeyHeader, idempotencyKeyOf(ctx, account))
+	// account IDs are generated on the client side, so a retried create can't duplicate the account
+	req = transport.AllowRetry(req)
+	op := c.operation("Create", account.ID, account.Version)
+	op.OrganisationID = account.OrganisationID
+	var res *CreateSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return c.create(req, account)
+	})
+	return res, err
+}
+
+func (c *httpClient) create(req *http.Request, account *Data) (*CreateSuccess, error) {
 	var res CreateSuccess
-	resp, err := c.do(req, &res)
+	resp, err := c.t.Do(req, &res)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode == 409 {
-		return nil, &AccountAlreadyExistsError{ID: account.ID}
+		if resp.Attempts > 1 || c.idempotentCreate {
+			// one of previous attempts, or calls, might have created the account
+			return c.reconcile(req.Context(), account, resp.APIError())
+		}
+		return nil, &AccountAlreadyExistsError{APIError: resp.APIError(), ID: account.ID}
 	}
-
-	err = checkStatusCode(resp)
-	return &res, err
+	return &res, transport.CheckStatusCode(resp)
 }
 
 func (c *httpClient) Fetch(ctx context.Context, id string) (*FetchSuccess, error) {
 	url := fmt.Sprintf("%s/%s", accountsBasePath, id)
-	req, err := c.newRequest(ctx, "GET", url, nil)
+	req, err := c.t.NewRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
+	op := c.operation("Fetch", id, nil)
+	var res *FetchSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return c.fetch(req, id)
+	})
+	return res, err
+}
+
+func (c *httpClient) fetch(req *http.Request, id string) (*FetchSuccess, error) {
 	var res FetchSuccess
-	resp, err := c.do(req, &res)
+	resp, err := c.t.Do(req, &res)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode == 404 {
-		return nil, &AccountNotFoundError{ID: id}
+		return nil, &AccountNotFoundError{APIError: resp.APIError(), ID: id}
 	}
 
-	err = checkStatusCode(resp)
+	err = transport.CheckStatusCode(resp)
+	if err == nil {
+		err = c.checkEnums(res.Data)
+	}
 	return &res, err
 }
 
-func (c *httpClient) Delete(ctx context.Context, id string, ver int64) error {
-	url := fmt.Sprintf("%s/%s?version=%d", accountsBasePath, id, ver)
-	req, err := c.newRequest(ctx, "DELETE", url, nil)
+func (c *httpClient) Update(ctx context.Context, id string, ver int64, patch *Attributes) (*UpdateSuccess, error) {
+	url := fmt.Sprintf("%s/%s", accountsBasePath, id)
+	if err := c.checkEnums(&Data{Attributes: patch}); err != nil {
+		return nil, err
+	}
+	body := transport.Envelope{Data: &Data{ID: id, Type: Type, Version: &ver, Attributes: patch}}
+	req, err := c.t.NewRequest(ctx, "PATCH", url, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	resp, err := c.do(req, nil)
+	op := c.operation("Update", id, &ver)
+	var res *UpdateSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return c.update(req, id, ver)
+	})
+	return res, err
+}
+
+func (c *httpClient) update(req *http.Request, id string, ver int64) (*UpdateSuccess, error) {
+	var res UpdateSuccess
+	resp, err := c.t.Do(req, &res)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if resp.StatusCode == 404 {
-		return &AccountNotFoundError{ID: id}
+		return nil, &AccountNotFoundError{APIError: resp.APIError(), ID: id}
 	} else if resp.StatusCode == 409 {
-		return &InvalidVersionError{Ver: ver}
+		return nil, &InvalidVersionError{APIError: resp.APIError(), Ver: ver}
 	}
-	return checkStatusCode(resp)
+	return &res, transport.CheckStatusCode(resp)
 }
 
-func checkStatusCode(resp *http.Response) error {
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return &HttpStatusError{StatusCode: resp.StatusCode}
+func (c *httpClient) Delete(ctx context.Context, id string, ver int64) error {
+	url := fmt.Sprintf("%s/%s?version=%d", accountsBasePath, id, ver)
+	req, err := c.t.NewRequest(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
 	}
-	return nil
+	_, err = c.t.Invoke(c.operation("Delete", id, &ver), req, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return nil, c.delete(req, id, ver)
+	})
+	return err
 }
 
-func (c *httpClient) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
-	u, err := c.baseURL.Parse(path)
+func (c *httpClient) delete(req *http.Request, id string, ver int64) error {
+	resp, err := c.t.Do(req, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var buf io.ReadWriter
-	if body != nil {
-		buf = new(bytes.Buffer)
-		err := json.NewEncoder(buf).Encode(body)
-		if err != nil {
-			return nil, err
+
+	if resp.StatusCode == 404 {
+		if resp.Attempts > 1 {
+			// one of previous attempts has deleted the account
+			return nil
 		}
+		return &AccountNotFoundError{APIError: resp.APIError(), ID: id}
+	} else if resp.StatusCode == 409 {
+		return &InvalidVersionError{APIError: resp.APIError(), Ver: ver}
 	}
-	req, err := http.NewRequest(method, u.String(), buf)
-	if err != nil {
-		return nil, err
-	}
-	if body != nil {
-		req.Header.Set("Content-Type", "application/vnd.api+json")
+	return transport.CheckStatusCode(resp)
+}
+
+func (c *httpClient) List(ctx context.Context, opts ListOptions) (*ListSuccess, error) {
+	return c.list(ctx, "List", transport.ListPath(accountsBasePath, opts.query()))
+}
+
+func (c *httpClient) ListNext(ctx context.Context, page *ListSuccess) (*ListSuccess, error) {
+	if !page.HasNext() {
+		return nil, nil
 	}
-	req.Header.Set("Accept", "application/vnd.api+json")
-	req.Header.Set("Date", time.Now().Format(time.RFC1123))
-	return req.WithContext(ctx), nil
+	return c.list(ctx, "ListNext", *page.Links.Next)
 }
 
-func (c *httpClient) do(req *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := c.httpClient.Do(req)
+func (c *httpClient) list(ctx context.Context, name, url string) (*ListSuccess, error) {
+	req, err := c.t.NewRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 400 {
-		r := InvalidDataError{}
-		err := json.NewDecoder(resp.Body).Decode(&r)
+	op := c.operation(name, "", nil)
+	var res *ListSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		var res ListSuccess
+		resp, err := c.t.Do(req, &res)
 		if err != nil {
-			return resp, err
+			return nil, err
 		}
-		return resp, &r
-	}
 
-	if v != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		err = json.NewDecoder(resp.Body).Decode(v)
-	}
-	return resp, err
+		err = transport.CheckStatusCode(resp)
+		if err == nil {
+			err = c.checkEnums(res.Data...)
+		}
+		return &res, err
+	})
+	return res, err
+}
+
+func (c *httpClient) operation(name, id string, version *int64) *transport.Operation {
+	return &transport.Operation{Service: Type, Name: name, ResourceID: id, Version: version}
 }
 
-type createRequest struct {
-	Data *Data `json:"data"`
+func (c *httpClient) checkEnums(accounts ...*Data) error {
+	if !c.strictEnums {
+		return nil
+	}
+	for _, a := range accounts {
+		if a == nil {
+			continue
+		}
+		if err := checkEnums(a.Attributes); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -3,12 +3,14 @@ package accounts
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"testing"
 
+	"github.com/althink/form3/internal/transport"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,17 +70,17 @@ func Test_Accounts_CreateSuccess(t *testing.T) {
 	require.Equal(t, "GBP", created.Data.Attributes.BaseCurrency, "Invalid BaseCurrency")
 	require.Equal(t, "41426819", created.Data.Attributes.AccountNumber, "Invalid AccountNumber")
 	require.Equal(t, "400300", created.Data.Attributes.BankID, "Invalid BankID")
-	require.Equal(t, "GBDSC", created.Data.Attributes.BankIDCode, "Invalid BankIDCode")
+	require.Equal(t, BankIDCodeGB, created.Data.Attributes.BankIDCode, "Invalid BankIDCode")
 	require.Equal(t, "NWBKGB22", created.Data.Attributes.Bic, "Invalid Bic")
 	require.Equal(t, "GB11NWBK40030041426819", created.Data.Attributes.Iban, "Invalid Iban")
 	require.Equal(t, []string{"Samantha Holder"}, created.Data.Attributes.Name, "Invalid Name")
 	require.Equal(t, []string{"Sam Holder"}, created.Data.Attributes.AlternativeNames, "Invalid AlternativeNames")
-	require.Equal(t, "confirmed", *created.Data.Attributes.Status, "Invalid Status")
+	require.Equal(t, StatusConfirmed, *created.Data.Attributes.Status, "Invalid Status")
 	require.Equal(t, []UserDefinedData{{Key: "Some account related key", Value: "Some account related value"}}, created.Data.Attributes.UserDefinedData, "Invalid UserDefinedData")
-	require.Equal(t, "card", *created.Data.Attributes.ValidationType, "Invalid ValidationType")
+	require.Equal(t, ValidationTypeCard, *created.Data.Attributes.ValidationType, "Invalid ValidationType")
 	require.Equal(t, "############", *created.Data.Attributes.ReferenceMask, "Invalid ReferenceMask")
-	require.Equal(t, "same_day", *created.Data.Attributes.AcceptanceQualifier, "Invalid AcceptanceQualifier")
-	require.Equal(t, "Personal", *created.Data.Attributes.AccountClassification, "Invalid AccountClassification")
+	require.Equal(t, AcceptanceQualifierSameDay, *created.Data.Attributes.AcceptanceQualifier, "Invalid AcceptanceQualifier")
+	require.Equal(t, ClassificationPersonal, *created.Data.Attributes.AccountClassification, "Invalid AccountClassification")
 	require.Equal(t, false, *created.Data.Attributes.JointAccount, "Invalid JointAccount")
 	require.Equal(t, false, *created.Data.Attributes.AccountMatchingOptOut, "Invalid AccountMatchingOptOut")
 	require.Equal(t, "A1B2C3D4", *created.Data.Attributes.SecondaryIdentification, "Invalid SecondaryIdentification")
@@ -133,6 +135,30 @@ func Test_Accounts_CreateFailed_500Error(t *testing.T) {
 	require.Equal(t, responseCode, err.(*HttpStatusError).StatusCode)
 }
 
+func Test_Accounts_CreateSuccess_RetriedAlreadyExists(t *testing.T) {
+	// given
+	ctx := context.Background()
+	posts := 0
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, `{"data": {"id": "eb89cce1-3b1f-4b37-967f-23354c5ad61e", "version": 0}}`), nil
+		}
+		posts++
+		if posts == 1 {
+			return buildResponse(503, ``), nil
+		}
+		return buildResponse(409, ``), nil
+	}, withTransportOptions(transport.WithRetryPolicy(transport.RetryPolicy{MaxAttempts: 2})))
+
+	// when
+	created, err := c.Create(ctx, &Data{ID: "eb89cce1-3b1f-4b37-967f-23354c5ad61e"})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 2, posts, "Invalid number of attempts")
+	require.Equal(t, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", created.Data.ID, "Invalid ID")
+}
+
 func Test_Accounts_FetchSuccess(t *testing.T) {
 	// given
 	ctx := context.Background()
@@ -211,6 +237,58 @@ func Test_Accounts_FetchFailed_500Error(t *testing.T) {
 	require.Equal(t, responseCode, err.(*HttpStatusError).StatusCode)
 }
 
+func Test_Accounts_FetchFailed_500Error_Details(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(func(*http.Request) (*http.Response, error) {
+		resp := buildResponse(500, `{"error_message": "database unavailable", "error_code": "internal"}`)
+		resp.Header = http.Header{"X-Request-Id": {"req-1"}}
+		return resp, nil
+	})
+
+	// when
+	_, err := c.Fetch(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e")
+
+	// then
+	require.IsType(t, &HttpStatusError{}, err, "Invalid error type")
+	e := err.(*HttpStatusError)
+	require.Equal(t, "GET", e.Method)
+	require.Equal(t, "http://form3/v1/organisation/accounts/eb89cce1-3b1f-4b37-967f-23354c5ad61e", e.URL)
+	require.Equal(t, "database unavailable", e.Msg)
+	require.Equal(t, "internal", e.Code)
+	require.Equal(t, "req-1", e.RequestID)
+	require.Equal(t, "error code returned: 500: database unavailable (request ID req-1)", e.Error())
+}
+
+func Test_Accounts_Errors_Sentinels(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		switch req.Method {
+		case "GET":
+			return buildResponse(404, ``), nil
+		case "PATCH":
+			return buildResponse(409, `{"error_message": "invalid version"}`), nil
+		}
+		return buildResponse(429, ``), nil
+	})
+
+	// when
+	_, fetchErr := c.Fetch(ctx, "1")
+	_, updateErr := c.Update(ctx, "1", 0, &Attributes{})
+	deleteErr := c.Delete(ctx, "1", 0)
+
+	// then
+	require.True(t, errors.Is(fetchErr, ErrNotFound))
+	require.False(t, errors.Is(fetchErr, ErrConflict))
+	require.True(t, errors.Is(updateErr, ErrConflict))
+	require.True(t, errors.Is(deleteErr, ErrRateLimited))
+	apiErr, ok := AsAPIError(updateErr)
+	require.True(t, ok)
+	require.Equal(t, 409, apiErr.StatusCode)
+	require.Equal(t, "invalid version", apiErr.Msg)
+}
+
 func Test_Accounts_DeleteSuccess(t *testing.T) {
 	// given
 	ctx := context.Background()
@@ -225,6 +303,26 @@ func Test_Accounts_DeleteSuccess(t *testing.T) {
 	require.Empty(t, err)
 }
 
+func Test_Accounts_DeleteSuccess_RetriedNotFound(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return buildResponse(404, ``), nil
+	}, withTransportOptions(transport.WithRetryPolicy(transport.RetryPolicy{MaxAttempts: 2})))
+
+	// when
+	err := c.Delete(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", 0)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 2, calls, "Invalid number of attempts")
+}
+
 func Test_Accounts_DeleteFailed_UnknownAccount(t *testing.T) {
 	// given
 	ctx := context.Background()
@@ -289,12 +387,332 @@ func Test_Accounts_DeleteFailed_500Error(t *testing.T) {
 	require.Equal(t, responseCode, err.(*HttpStatusError).StatusCode)
 }
 
-func setUpMockClient(r RoundTrip) Service {
-	u, err := url.Parse("http://form3/v1")
+func Test_Accounts_UpdateSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 200
+	responseBody := `{
+						"data": {
+							"type": "accounts",
+							"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+							"version": 3,
+							"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+							"attributes": {
+								"country": "GB",
+								"customer_id": "customer-1"
+							}
+						}
+		  			}`
+	var method, reqURL, reqBody string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		reqURL = req.URL.String()
+		b, _ := ioutil.ReadAll(req.Body)
+		reqBody = string(b)
+		return buildResponse(responseCode, responseBody), nil
+	})
+
+	// when
+	updated, err := c.Update(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 2, &Attributes{CustomerID: "customer-1"})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "PATCH", method, "Invalid method")
+	require.Equal(t, "http://form3/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", reqURL, "Invalid request URL")
+	require.JSONEq(t, `{
+						"data": {
+							"type": "accounts",
+							"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+							"version": 2,
+							"attributes": {
+								"customer_id": "customer-1"
+							}
+						}
+					}`, reqBody, "Invalid request body")
+	require.Equal(t, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", updated.Data.ID, "Invalid ID")
+	require.Equal(t, int64(3), *updated.Data.Version, "Invalid Version")
+	require.Equal(t, "customer-1", updated.Data.Attributes.CustomerID, "Invalid CustomerID")
+}
+
+func Test_Accounts_UpdateFailed_UnknownAccount(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 404
+	responseBody := ``
+	c := setUpMockClient(withResponse(responseCode, responseBody))
+
+	// when
+	_, err := c.Update(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", 0, &Attributes{})
+
+	// then
+	require.IsType(t, &AccountNotFoundError{}, err, "Invalid error type")
+	require.Equal(t, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", err.(*AccountNotFoundError).ID)
+}
+
+func Test_Accounts_UpdateFailed_InvalidVersion(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 409
+	responseBody := ``
+	c := setUpMockClient(withResponse(responseCode, responseBody))
+
+	// when
+	_, err := c.Update(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", 1, &Attributes{})
+
+	// then
+	require.IsType(t, &InvalidVersionError{}, err, "Invalid error type")
+	require.Equal(t, int64(1), err.(*InvalidVersionError).Ver)
+}
+
+func Test_Accounts_UpdateFailed_500Error(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 500
+	responseBody := ``
+	c := setUpMockClient(withResponse(responseCode, responseBody))
+
+	// when
+	_, err := c.Update(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", 1, &Attributes{})
+
+	// then
+	require.IsType(t, &HttpStatusError{}, err, "Invalid error type")
+	require.Equal(t, responseCode, err.(*HttpStatusError).StatusCode)
+}
+
+func Test_Accounts_ListSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 200
+	responseBody := `{
+						"data": [
+							{
+								"type": "accounts",
+								"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+								"version": 0,
+								"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+								"attributes": {
+									"country": "GB"
+								}
+							},
+							{
+								"type": "accounts",
+								"id": "eb89cce1-3b1f-4b37-967f-23354c5ad61e",
+								"version": 2,
+								"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+								"attributes": {
+									"country": "GB"
+								}
+							}
+						],
+						"links": {
+							"first": "/v1/organisation/accounts?page%5Bnumber%5D=first&page%5Bsize%5D=2",
+							"last": "/v1/organisation/accounts?page%5Bnumber%5D=last&page%5Bsize%5D=2",
+							"next": "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2",
+							"prev": "/v1/organisation/accounts?page%5Bnumber%5D=0&page%5Bsize%5D=2",
+							"self": "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2"
+						}
+					}`
+	var reqURL string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURL = req.URL.String()
+		return buildResponse(responseCode, responseBody), nil
+	})
+
+	// when
+	list, err := c.List(ctx, ListOptions{
+		PageNumber: 1,
+		PageSize:   2,
+		Filter:     ListFilter{Country: "GB", BankID: "400300"},
+	})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/organisation/accounts?filter%5Bbank_id%5D=400300&filter%5Bcountry%5D=GB&page%5Bnumber%5D=1&page%5Bsize%5D=2", reqURL, "Invalid request URL")
+	require.Len(t, list.Data, 2)
+	require.Equal(t, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", list.Data[0].ID, "Invalid ID")
+	require.Equal(t, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", list.Data[1].ID, "Invalid ID")
+	require.Equal(t, int64(2), *list.Data[1].Version, "Invalid Version")
+	require.Equal(t, "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2", *list.Links.Self, "Invalid Self link")
+	require.Equal(t, "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2", *list.Links.Next, "Invalid Next link")
+	require.True(t, list.HasNext())
+}
+
+func Test_Accounts_ListNextSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 200
+	responseBody := `{
+						"data": [],
+						"links": {
+							"self": "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2"
+						}
+					}`
+	var reqURL string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURL = req.URL.String()
+		return buildResponse(responseCode, responseBody), nil
+	})
+	next := "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2"
+	page := &ListSuccess{Links: &ListLinks{Next: &next}}
+
+	// when
+	list, err := c.ListNext(ctx, page)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2", reqURL, "Invalid request URL")
+	require.Empty(t, list.Data)
+	require.False(t, list.HasNext())
+
+	// when
+	last, err := c.ListNext(ctx, list)
+
+	// then
+	require.Empty(t, err)
+	require.Nil(t, last)
+}
+
+func Test_Accounts_ListNextFailed_ForeignLink(t *testing.T) {
+	for _, next := range []string{
+		"https://attacker.example/v1/organisation/accounts?page%5Bnumber%5D=2",
+		"https://form3/v1/organisation/accounts?page%5Bnumber%5D=2",
+		"//attacker.example/v1/organisation/accounts?page%5Bnumber%5D=2",
+	} {
+		t.Run(next, func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			called := false
+			c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+				called = true
+				return buildResponse(200, `{"data": []}`), nil
+			})
+			page := &ListSuccess{Links: &ListLinks{Next: &next}}
+
+			// when
+			_, err := c.ListNext(ctx, page)
+
+			// then
+			require.IsType(t, &ForeignURLError{}, err, "Invalid error type")
+			require.False(t, called, "Request should not be sent")
+		})
+	}
+}
+
+func Test_Accounts_ListFailed_InvalidData(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 400
+	responseBody := `{
+						"error_message": "some error message", 
+						"error_code":"b5930880-1001-453b-86bd-2c5e29bd98d7"
+					}`
+	c := setUpMockClient(withResponse(responseCode, responseBody))
+
+	// when
+	_, err := c.List(ctx, ListOptions{PageSize: -1})
+
+	// then
+	require.IsType(t, &InvalidDataError{}, err, "Invalid error type")
+	require.Equal(t, "some error message", err.(*InvalidDataError).Msg)
+}
+
+func Test_Accounts_ListFailed_500Error(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseCode := 500
+	responseBody := ``
+	c := setUpMockClient(withResponse(responseCode, responseBody))
+
+	// when
+	_, err := c.List(ctx, ListOptions{})
+
+	// then
+	require.IsType(t, &HttpStatusError{}, err, "Invalid error type")
+	require.Equal(t, responseCode, err.(*HttpStatusError).StatusCode)
+}
+
+func Test_Accounts_MiddlewareObservesOperation(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var op transport.Operation
+	var observed error
+	audit := func(next transport.Invoker) transport.Invoker {
+		return func(o *transport.Operation, req *http.Request) (interface{}, error) {
+			v, err := next(o, req)
+			op, observed = *o, err
+			return v, err
+		}
+	}
+	c := setUpMockClient(withResponse(409, ``), withTransportOptions(transport.WithMiddleware(audit)))
+
+	// when
+	err := c.Delete(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", 3)
+
+	// then
+	require.IsType(t, &InvalidVersionError{}, observed, "Invalid error type")
+	require.Equal(t, observed, err)
+	require.Equal(t, "accounts", op.Service)
+	require.Equal(t, "Delete", op.Name)
+	require.Equal(t, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", op.ResourceID)
+	require.EqualValues(t, 3, *op.Version)
+	require.Equal(t, 1, op.Attempts)
+}
+
+func Test_Accounts_MiddlewareShortCircuits(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake := func(next transport.Invoker) transport.Invoker {
+		return func(op *transport.Operation, req *http.Request) (interface{}, error) {
+			if op.Name == "Fetch" {
+				return &FetchSuccess{Data: &Data{ID: op.ResourceID}}, nil
+			}
+			return next(op, req)
+		}
+	}
+	c := setUpMockClient(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("should not be called")
+	}, withTransportOptions(transport.WithMiddleware(fake)))
+
+	// when
+	res, err := c.Fetch(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e")
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", res.Data.ID)
+}
+
+func Test_Accounts_MiddlewareShortCircuitsFailed_UnexpectedResult(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake := func(next transport.Invoker) transport.Invoker {
+		return func(op *transport.Operation, req *http.Request) (interface{}, error) {
+			return FetchSuccess{}, nil
+		}
+	}
+	c := setUpMockClient(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("should not be called")
+	}, withTransportOptions(transport.WithMiddleware(fake)))
+
+	// when
+	res, err := c.Fetch(ctx, "eb89cce1-3b1f-4b37-967f-23354c5ad61e")
+
+	// then
+	require.Nil(t, res)
+	require.IsType(t, &UnexpectedResultError{}, err, "Invalid error type")
+	require.Equal(t, "unexpected result of accounts Fetch: got accounts.FetchSuccess, want *accounts.FetchSuccess", err.Error())
+}
+
+func setUpMockClient(r RoundTrip, opts ...ClientOption) Service {
+	u, err := url.Parse("http://form3/v1/")
 	if err != nil {
 		log.Fatal(err)
 	}
-	return NewClient(&http.Client{Transport: r}, *u)
+	return NewClient(&http.Client{Transport: r}, *u, opts...)
+}
+
+// withTransportOptions builds a ClientOption adding given transport options, like form3.NewClient does
+func withTransportOptions(opts ...transport.Option) ClientOption {
+	return ClientOption(transport.Configure(opts...))
 }
 
 // withResponse builds a RoundTrip function that returns HTTP response with given statusCode and body
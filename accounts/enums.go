@@ -0,0 +1,141 @@
+package accounts
+
+// Enumerated attribute values.
+//
+// Values unknown to this library are kept as they are by default, so the client keeps working
+// when Form3 adds new values. With WithStrictEnums client option unknown values are rejected
+// with UnknownEnumError, before requests are sent and in responses of Fetch and List.
+//
+// Strict checks run on decoded accounts rather than in UnmarshalJSON and MarshalJSON, because
+// strictness is an option of a client, not of the types. Responses of Create and Update aren't
+// checked, because the account is already written when they are received.
+
+// Status of the account, see Attributes.Status
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusConfirmed Status = "confirmed"
+	StatusClosed    Status = "closed"
+	StatusFailed    Status = "failed"
+)
+
+func (s Status) IsKnown() bool {
+	switch s {
+	case StatusPending, StatusConfirmed, StatusClosed, StatusFailed:
+		return true
+	}
+	return false
+}
+
+// FPS reason code of the account status, see Attributes.StatusReason
+type StatusReason string
+
+const (
+	StatusReasonUnspecified        StatusReason = "unspecified"
+	StatusReasonInvalidAccountType StatusReason = "invalid-account-type"
+	StatusReasonAccountClosed      StatusReason = "account-closed"
+	StatusReasonAccountTransferred StatusReason = "account-transferred"
+	StatusReasonAccountSwitched    StatusReason = "account-switched"
+	StatusReasonDeceased           StatusReason = "deceased"
+)
+
+func (r StatusReason) IsKnown() bool {
+	switch r {
+	case StatusReasonUnspecified, StatusReasonInvalidAccountType, StatusReasonAccountClosed,
+		StatusReasonAccountTransferred, StatusReasonAccountSwitched, StatusReasonDeceased:
+		return true
+	}
+	return false
+}
+
+// Confirmation of Payee classification of the account, see Attributes.AccountClassification
+type AccountClassification string
+
+const (
+	ClassificationPersonal AccountClassification = "Personal"
+	ClassificationBusiness AccountClassification = "Business"
+)
+
+func (c AccountClassification) IsKnown() bool {
+	return c == ClassificationPersonal || c == ClassificationBusiness
+}
+
+// FPS acceptance qualifier, see Attributes.AcceptanceQualifier
+type AcceptanceQualifier string
+
+const (
+	AcceptanceQualifierSameDay       AcceptanceQualifier = "same_day"
+	AcceptanceQualifierNextDay       AcceptanceQualifier = "next_day"
+	AcceptanceQualifierStandingOrder AcceptanceQualifier = "standing_order"
+)
+
+func (q AcceptanceQualifier) IsKnown() bool {
+	switch q {
+	case AcceptanceQualifierSameDay, AcceptanceQualifierNextDay, AcceptanceQualifierStandingOrder:
+		return true
+	}
+	return false
+}
+
+// Validations carried out on inbound payments, see Attributes.ValidationType
+type ValidationType string
+
+const (
+	ValidationTypeCard ValidationType = "card"
+)
+
+func (t ValidationType) IsKnown() bool {
+	return t == ValidationTypeCard
+}
+
+// Type of the bank ID, see Attributes.BankIDCode
+type BankIDCode string
+
+const (
+	BankIDCodeGB BankIDCode = "GBDSC"
+	BankIDCodeAU BankIDCode = "AUBSB"
+	BankIDCodeBE BankIDCode = "BE"
+	BankIDCodeCA BankIDCode = "CACPA"
+	BankIDCodeFR BankIDCode = "FR"
+	BankIDCodeDE BankIDCode = "DEBLZ"
+	BankIDCodeGR BankIDCode = "GRBIC"
+	BankIDCodeHK BankIDCode = "HKNCC"
+	BankIDCodeIT BankIDCode = "ITNCC"
+	BankIDCodeLU BankIDCode = "LULUX"
+	BankIDCodePL BankIDCode = "PLKNR"
+	BankIDCodePT BankIDCode = "PTNCC"
+	BankIDCodeES BankIDCode = "ESNCC"
+	BankIDCodeCH BankIDCode = "CHBCC"
+	BankIDCodeUS BankIDCode = "USABA"
+)
+
+func (c BankIDCode) IsKnown() bool {
+	switch c {
+	case BankIDCodeGB, BankIDCodeAU, BankIDCodeBE, BankIDCodeCA, BankIDCodeFR, BankIDCodeDE, BankIDCodeGR,
+		BankIDCodeHK, BankIDCodeIT, BankIDCodeLU, BankIDCodePL, BankIDCodePT, BankIDCodeES, BankIDCodeCH, BankIDCodeUS:
+		return true
+	}
+	return false
+}
+
+// checkEnums returns UnknownEnumError for the first attribute with an unknown value.
+func checkEnums(a *Attributes) error {
+	switch {
+	case a == nil:
+		return nil
+	case a.Status != nil && !a.Status.IsKnown():
+		return &UnknownEnumError{Field: "status", Value: string(*a.Status)}
+	case a.StatusReason != nil && !a.StatusReason.IsKnown():
+		return &UnknownEnumError{Field: "status_reason", Value: string(*a.StatusReason)}
+	case a.AccountClassification != nil && !a.AccountClassification.IsKnown():
+		return &UnknownEnumError{Field: "account_classification", Value: string(*a.AccountClassification)}
+	case a.AcceptanceQualifier != nil && !a.AcceptanceQualifier.IsKnown():
+		return &UnknownEnumError{Field: "acceptance_qualifier", Value: string(*a.AcceptanceQualifier)}
+	case a.ValidationType != nil && !a.ValidationType.IsKnown():
+		return &UnknownEnumError{Field: "validation_type", Value: string(*a.ValidationType)}
+	case a.BankIDCode != "" && !a.BankIDCode.IsKnown():
+		return &UnknownEnumError{Field: "bank_id_code", Value: string(a.BankIDCode)}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package accounts
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const unknownStatusBody = `{
+							"data": {
+								"type": "accounts",
+								"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+								"version": 0,
+								"attributes": {
+									"country": "GB",
+									"status": "frozen"
+								}
+							}
+						}`
+
+func Test_Accounts_FetchSuccess_UnknownEnumTolerated(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(200, unknownStatusBody))
+
+	// when
+	fetched, err := c.Fetch(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, Status("frozen"), *fetched.Data.Attributes.Status, "Invalid Status")
+	require.False(t, fetched.Data.Attributes.Status.IsKnown())
+}
+
+func Test_Accounts_FetchFailed_UnknownEnumRejected(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(200, unknownStatusBody), WithStrictEnums())
+
+	// when
+	_, err := c.Fetch(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+
+	// then
+	require.IsType(t, &UnknownEnumError{}, err, "Invalid error type")
+	require.Equal(t, "status", err.(*UnknownEnumError).Field)
+	require.Equal(t, "frozen", err.(*UnknownEnumError).Value)
+}
+
+func Test_Accounts_CreateFailed_UnknownEnumRejected(t *testing.T) {
+	// given
+	ctx := context.Background()
+	called := false
+	c := setUpMockClient(func(*http.Request) (*http.Response, error) {
+		called = true
+		return buildResponse(201, `{}`), nil
+	}, WithStrictEnums())
+
+	// when
+	_, err := c.Create(ctx, NewWithGenID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", &Attributes{
+		Country:    "GB",
+		BankIDCode: "SORTCODE",
+	}))
+
+	// then
+	require.IsType(t, &UnknownEnumError{}, err, "Invalid error type")
+	require.Equal(t, "bank_id_code", err.(*UnknownEnumError).Field)
+	require.False(t, called, "Request should not be sent")
+}
+
+func Test_Accounts_CreateSuccess_UnknownEnumReturnedNotRejected(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(201, unknownStatusBody), WithStrictEnums())
+
+	// when
+	created, err := c.Create(ctx, NewWithGenID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", &Attributes{
+		Country:    "GB",
+		BankIDCode: BankIDCodeGB,
+	}))
+
+	// then
+	require.Empty(t, err, "Created account should be returned")
+	require.Equal(t, Status("frozen"), *created.Data.Attributes.Status, "Invalid Status")
+}
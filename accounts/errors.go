@@ -1,17 +1,46 @@
 package accounts
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
 
-type InvalidDataError struct {
-	Code string `json:"error_code"`
-	Msg  string `json:"error_message"`
-}
+	"github.com/althink/form3/internal/transport"
+)
+
+// APIError describes a failed call: request method and URL, response status, headers, request ID,
+// raw body (up to 64KiB) and error details parsed from it. It's embedded in all errors returned
+// for server responses. Errors detected on the client side have it empty.
+type APIError = transport.APIError
+
+// ErrorObject is a JSON:API error object, see APIError.Errors
+type ErrorObject = transport.ErrorObject
+
+// Sentinel errors, matched with errors.Is by errors of failed calls.
+var (
+	ErrInvalidData = transport.ErrInvalidData
+	ErrNotFound    = transport.ErrNotFound
+	ErrConflict    = transport.ErrConflict
+	ErrRateLimited = transport.ErrRateLimited
+)
 
-func (e *InvalidDataError) Error() string {
-	return e.Msg
+// AsAPIError returns APIError embedded in the first error in err's chain that has one.
+func AsAPIError(err error) (*APIError, bool) {
+	return transport.AsAPIError(err)
 }
 
+type InvalidDataError = transport.InvalidDataError
+
+type HttpStatusError = transport.HttpStatusError
+
+type RateLimitedError = transport.RateLimitedError
+
+type UnexpectedResultError = transport.UnexpectedResultError
+
+type ForeignURLError = transport.ForeignURLError
+
 type AccountNotFoundError struct {
+	APIError
+
 	ID string
 }
 
@@ -19,7 +48,13 @@ func (e *AccountNotFoundError) Error() string {
 	return fmt.Sprintf("account not found: %s", e.ID)
 }
 
+func (e *AccountNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 type AccountAlreadyExistsError struct {
+	APIError
+
 	ID string
 }
 
@@ -27,7 +62,13 @@ func (e *AccountAlreadyExistsError) Error() string {
 	return fmt.Sprintf("account already exists: %s", e.ID)
 }
 
+func (e *AccountAlreadyExistsError) Is(target error) bool {
+	return target == ErrConflict
+}
+
 type InvalidVersionError struct {
+	APIError
+
 	Ver int64
 }
 
@@ -35,10 +76,97 @@ func (e *InvalidVersionError) Error() string {
 	return fmt.Sprintf("invalid version: %d", e.Ver)
 }
 
-type HttpStatusError struct {
-	StatusCode int
+func (e *InvalidVersionError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+type FieldError struct {
+	// JSON path of the invalid field, e.g. "attributes.bank_id"
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Msg)
+}
+
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("invalid account: %s", strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) add(field, msg string) {
+	e.Fields = append(e.Fields, &FieldError{Field: field, Msg: msg})
+}
+
+type InvalidBICError struct {
+	BIC    string
+	Reason string
+}
+
+func (e *InvalidBICError) Error() string {
+	return fmt.Sprintf("invalid BIC %q: %s", e.BIC, e.Reason)
+}
+
+type UnknownEnumError struct {
+	// JSON name of the attribute, e.g. "status"
+	Field string
+	Value string
+}
+
+func (e *UnknownEnumError) Error() string {
+	return fmt.Sprintf("unknown %s value: %q", e.Field, e.Value)
+}
+
+type IllegalTransitionError struct {
+	Scheme Scheme
+	From   Status
+	To     Status
+	Reason *StatusReason
+	Msg    string
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("illegal %s account status transition from %s to %s: %s", e.Scheme, e.From, e.To, e.Msg)
+}
+
+// ClearedFieldsError is returned by Mutate when the mutation clears fields, which can't be done with Update.
+type ClearedFieldsError struct {
+	ID string
+
+	// JSON paths of cleared fields, e.g. "attributes.status"
+	Fields []string
+}
+
+func (e *ClearedFieldsError) Error() string {
+	return fmt.Sprintf("account %s fields can't be cleared: %s", e.ID, strings.Join(e.Fields, ", "))
+}
+
+// IdempotencyMismatchError is returned by Create when an account with the same ID exists,
+// but differs from the requested one, so the call can't be treated as a replay.
+type IdempotencyMismatchError struct {
+	APIError
+
+	ID  string
+	Key string
+
+	// JSON paths of requested fields with other values in the existing account, e.g. "attributes.bank_id"
+	Fields []string
+
+	Existing *Data
+}
+
+func (e *IdempotencyMismatchError) Error() string {
+	return fmt.Sprintf("account %s already exists with other %s", e.ID, strings.Join(e.Fields, ", "))
 }
 
-func (e *HttpStatusError) Error() string {
-	return fmt.Sprintf("error code returned: %d", e.StatusCode)
+func (e *IdempotencyMismatchError) Is(target error) bool {
+	return target == ErrConflict
 }
@@ -0,0 +1,98 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Header with the idempotency key of Create requests.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context with the idempotency key sent by Create.
+// By default the account ID is used as the key.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+func idempotencyKeyOf(ctx context.Context, account *Data) string {
+	if key, ok := ctx.Value(idempotencyKey{}).(string); ok && key != "" {
+		return key
+	}
+	return account.ID
+}
+
+// WithIdempotentCreate makes Create treat an existing account with the same ID as a replay
+// of a previous Create, e.g. one that timed out. The existing account is fetched and compared
+// with the requested one: when they are equivalent it's returned as created, otherwise
+// IdempotencyMismatchError is returned. Conflicts of retried attempts are always handled this way.
+func WithIdempotentCreate() ClientOption {
+	return option(func(c *httpClient) {
+		c.idempotentCreate = true
+	})
+}
+
+// reconcile fetches the existing account and compares it with the requested one.
+func (c *httpClient) reconcile(ctx context.Context, account *Data, conflict APIError) (*CreateSuccess, error) {
+	fetched, err := c.Fetch(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	if fields := diff(account, fetched.Data); len(fields) > 0 {
+		return nil, &IdempotencyMismatchError{
+			APIError: conflict,
+			ID:       account.ID,
+			Key:      idempotencyKeyOf(ctx, account),
+			Fields:   fields,
+			Existing: fetched.Data,
+		}
+	}
+	return &CreateSuccess{Data: fetched.Data, Links: fetched.Links}, nil
+}
+
+// diff returns JSON paths of fields set in the requested account that have other values
+// in the existing one. Fields not set in the request, like generated account numbers
+// or the version, are not compared.
+func diff(requested, existing *Data) []string {
+	var want, got interface{}
+	if err := roundTrip(requested, &want); err != nil {
+		return []string{"data"}
+	}
+	if err := roundTrip(existing, &got); err != nil {
+		return []string{"data"}
+	}
+	var fields []string
+	subset(want, got, "", &fields)
+	sort.Strings(fields)
+	return fields
+}
+
+func roundTrip(v interface{}, out *interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func subset(want, got interface{}, path string, fields *[]string) {
+	w, ok := want.(map[string]interface{})
+	if !ok {
+		if !reflect.DeepEqual(want, got) {
+			*fields = append(*fields, path)
+		}
+		return
+	}
+	g, _ := got.(map[string]interface{})
+	for k, v := range w {
+		p := k
+		if path != "" {
+			p = fmt.Sprintf("%s.%s", path, k)
+		}
+		subset(v, g[k], p, fields)
+	}
+}
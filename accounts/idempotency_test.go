@@ -0,0 +1,92 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const existingAccount = `{"data": {"id": "eb89cce1-3b1f-4b37-967f-23354c5ad61e", "organisation_id": "org-1", "type": "accounts", "version": 0,
+	"attributes": {"country": "GB", "bank_id": "400300", "account_number": "41426819", "account_classification": "Personal"}}}`
+
+func Test_Accounts_CreateIdempotencyKey(t *testing.T) {
+	// given
+	var keys []string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		keys = append(keys, req.Header.Get(IdempotencyKeyHeader))
+		return buildResponse(201, existingAccount), nil
+	})
+	account := New("eb89cce1-3b1f-4b37-967f-23354c5ad61e", "org-1", &Attributes{Country: "GB"})
+
+	// when
+	_, err1 := c.Create(context.Background(), account)
+	_, err2 := c.Create(WithIdempotencyKey(context.Background(), "order-42"), account)
+
+	// then
+	require.Empty(t, err1)
+	require.Empty(t, err2)
+	require.Equal(t, []string{"eb89cce1-3b1f-4b37-967f-23354c5ad61e", "order-42"}, keys)
+}
+
+func Test_Accounts_CreateSuccess_IdempotentReplay(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, existingAccount), nil
+		}
+		return buildResponse(409, `{"error_message": "Account cannot be created as it violates a duplicate constraint"}`), nil
+	}, WithIdempotentCreate())
+	account := New("eb89cce1-3b1f-4b37-967f-23354c5ad61e", "org-1", &Attributes{Country: "GB", BankID: "400300"})
+
+	// when
+	created, err := c.Create(ctx, account)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "41426819", created.Data.Attributes.AccountNumber)
+}
+
+func Test_Accounts_CreateFailed_IdempotencyMismatch(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, existingAccount), nil
+		}
+		return buildResponse(409, ``), nil
+	}, WithIdempotentCreate())
+	account := New("eb89cce1-3b1f-4b37-967f-23354c5ad61e", "org-1", &Attributes{Country: "GB", BankID: "400301", Name: []string{"Jane"}})
+
+	// when
+	_, err := c.Create(ctx, account)
+
+	// then
+	require.IsType(t, &IdempotencyMismatchError{}, err, "Invalid error type")
+	e := err.(*IdempotencyMismatchError)
+	require.Equal(t, []string{"attributes.bank_id", "attributes.name"}, e.Fields)
+	require.Equal(t, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", e.Key)
+	require.Equal(t, 409, e.StatusCode)
+	require.Equal(t, "41426819", e.Existing.Attributes.AccountNumber)
+	require.True(t, errors.Is(err, ErrConflict))
+}
+
+func Test_Accounts_CreateFailed_AlreadyExists_NotIdempotent(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fetched := false
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		fetched = fetched || req.Method == "GET"
+		return buildResponse(409, ``), nil
+	})
+
+	// when
+	_, err := c.Create(ctx, New("eb89cce1-3b1f-4b37-967f-23354c5ad61e", "org-1", &Attributes{Country: "GB"}))
+
+	// then
+	require.IsType(t, &AccountAlreadyExistsError{}, err, "Invalid error type")
+	require.False(t, fetched)
+}
@@ -0,0 +1,188 @@
+package accounts
+
+import (
+	"context"
+)
+
+const defaultPrefetch = 1
+
+// AccountIterator walks lazily through all accounts matching given ListOptions.
+// Pages are fetched in background by following ListLinks.Next, so the next page
+// is usually ready before the current one is consumed.
+//
+//	it := accounts.Iterate(ctx, f3.Accounts, accounts.ListOptions{PageSize: 100})
+//	defer it.Close()
+//	for it.Next() {
+//		acc := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type AccountIterator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	pages  chan pageResult
+	wants  chan struct{}
+
+	page   []*Data
+	cur    *Data
+	err    error
+	closed bool
+}
+
+type pageResult struct {
+	page *ListSuccess
+	err  error
+}
+
+type iteratorConfig struct {
+	prefetch int
+}
+
+type IteratorOption func(*iteratorConfig)
+
+// WithPrefetch sets how many pages can be fetched ahead of the one being consumed.
+// Defaults to 1. Zero means a page is requested only when Next needs it,
+// i.e. when all accounts of the previous page are consumed.
+func WithPrefetch(pages int) IteratorOption {
+	return func(c *iteratorConfig) {
+		if pages >= 0 {
+			c.prefetch = pages
+		}
+	}
+}
+
+// Iterate returns an iterator over all accounts matching given options.
+// Iteration stops when all pages are consumed, an error occurs or the context is cancelled.
+// Close should be called when the iterator is abandoned before the end.
+func Iterate(ctx context.Context, svc Service, opts ListOptions, iterOpts ...IteratorOption) *AccountIterator {
+	cfg := iteratorConfig{prefetch: defaultPrefetch}
+	for _, o := range iterOpts {
+		o(&cfg)
+	}
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	it := &AccountIterator{
+		ctx:    ctx,
+		cancel: cancel,
+		pages:  make(chan pageResult, cfg.prefetch),
+		wants:  make(chan struct{}, cfg.prefetch+1),
+	}
+	for i := 0; i < cfg.prefetch; i++ {
+		it.wants <- struct{}{}
+	}
+	go it.fetch(fetchCtx, svc, opts)
+	return it
+}
+
+// Next advances the iterator to the next account.
+// Returns false when there are no more accounts or iteration failed, see Err.
+func (it *AccountIterator) Next() bool {
+	it.cur = nil
+	if it.closed || it.err != nil {
+		return false
+	}
+	for {
+		if err := it.ctx.Err(); err != nil {
+			it.fail(err)
+			return false
+		}
+		if len(it.page) > 0 {
+			it.cur = it.page[0]
+			it.page = it.page[1:]
+			return true
+		}
+		it.want()
+		select {
+		case r, ok := <-it.pages:
+			if !ok {
+				if err := it.ctx.Err(); err != nil {
+					it.fail(err)
+				}
+				return false
+			}
+			if r.err != nil {
+				it.fail(r.err)
+				return false
+			}
+			it.page = r.page.Data
+		case <-it.ctx.Done():
+			it.fail(it.ctx.Err())
+			return false
+		}
+	}
+}
+
+// Value returns the current account. It's valid only after Next returned true.
+func (it *AccountIterator) Value() *Data {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// Close stops background fetching. It's safe to call Close multiple times.
+func (it *AccountIterator) Close() {
+	it.closed = true
+	it.cur = nil
+	it.page = nil
+	it.cancel()
+}
+
+func (it *AccountIterator) fail(err error) {
+	it.err = err
+	it.page = nil
+	it.cancel()
+}
+
+// want allows the fetching goroutine to request one more page. The goroutine requests
+// at most as many pages as Next needed plus prefetch, so wants never blocks on a full buffer.
+func (it *AccountIterator) want() {
+	select {
+	case it.wants <- struct{}{}:
+	default:
+	}
+}
+
+func (it *AccountIterator) fetch(ctx context.Context, svc Service, opts ListOptions) {
+	defer close(it.pages)
+
+	if !it.wait(ctx) {
+		return
+	}
+	page, err := svc.List(ctx, opts)
+	for {
+		if err != nil {
+			it.send(ctx, pageResult{err: err})
+			return
+		}
+		if page == nil || !it.send(ctx, pageResult{page: page}) || !page.HasNext() {
+			return
+		}
+		if !it.wait(ctx) {
+			return
+		}
+		page, err = svc.ListNext(ctx, page)
+	}
+}
+
+// wait blocks until the next page is wanted. Returns false when the context is done.
+func (it *AccountIterator) wait(ctx context.Context) bool {
+	select {
+	case <-it.wants:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (it *AccountIterator) send(ctx context.Context, r pageResult) bool {
+	select {
+	case it.pages <- r:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
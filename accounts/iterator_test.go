@@ -0,0 +1,130 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Accounts_IterateSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withPages(3, 2))
+
+	// when
+	it := Iterate(ctx, c, ListOptions{PageSize: 2}, WithPrefetch(2))
+	defer it.Close()
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+
+	// then
+	require.Empty(t, it.Err())
+	require.Equal(t, []string{"0-0", "0-1", "1-0", "1-1", "2-0", "2-1"}, ids)
+	require.Nil(t, it.Value())
+}
+
+func Test_Accounts_IterateSuccess_Prefetch(t *testing.T) {
+	for _, tc := range []struct {
+		prefetch int
+		requests []int32
+	}{
+		{prefetch: 0, requests: []int32{1, 1, 2, 2, 3}},
+		{prefetch: 1, requests: []int32{2, 2, 3, 3, 4}},
+	} {
+		t.Run(fmt.Sprintf("prefetch=%d", tc.prefetch), func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			var requests int32
+			pages := withPages(10, 2)
+			c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&requests, 1)
+				return pages(req)
+			})
+
+			// when
+			it := Iterate(ctx, c, ListOptions{PageSize: 2}, WithPrefetch(tc.prefetch))
+			defer it.Close()
+			var got []int32
+			for i := 0; i < len(tc.requests); i++ {
+				require.True(t, it.Next())
+				time.Sleep(20 * time.Millisecond)
+				got = append(got, atomic.LoadInt32(&requests))
+			}
+
+			// then
+			require.Empty(t, it.Err())
+			require.Equal(t, tc.requests, got)
+		})
+	}
+}
+
+func Test_Accounts_IterateFailed_500Error(t *testing.T) {
+	// given
+	ctx := context.Background()
+	pages := withPages(3, 2)
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("page[number]") == "1" {
+			return buildResponse(500, ``), nil
+		}
+		return pages(req)
+	})
+
+	// when
+	it := Iterate(ctx, c, ListOptions{PageSize: 2})
+	defer it.Close()
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+
+	// then
+	require.Equal(t, []string{"0-0", "0-1"}, ids)
+	require.IsType(t, &HttpStatusError{}, it.Err(), "Invalid error type")
+	require.False(t, it.Next())
+}
+
+func Test_Accounts_IterateStopsOnCancel(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	c := setUpMockClient(withPages(100, 2))
+
+	// when
+	it := Iterate(ctx, c, ListOptions{PageSize: 2})
+	defer it.Close()
+	require.True(t, it.Next())
+	cancel()
+
+	// then
+	require.False(t, it.Next())
+	require.Equal(t, context.Canceled, it.Err())
+}
+
+// withPages builds a RoundTrip function that serves given number of account pages.
+// Accounts have IDs in "<page>-<index>" format.
+func withPages(pages, size int) RoundTrip {
+	return func(req *http.Request) (*http.Response, error) {
+		var number int
+		fmt.Sscanf(req.URL.Query().Get("page[number]"), "%d", &number)
+
+		data := ""
+		for i := 0; i < size; i++ {
+			if i > 0 {
+				data += ","
+			}
+			data += fmt.Sprintf(`{"type": "accounts", "id": "%d-%d", "version": 0}`, number, i)
+		}
+		next := ""
+		if number+1 < pages {
+			next = fmt.Sprintf(`, "next": "/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D=%d"`, number+1, size)
+		}
+		body := fmt.Sprintf(`{"data": [%s], "links": {"self": "%s"%s}}`, data, req.URL.RequestURI(), next)
+		return buildResponse(200, body), nil
+	}
+}
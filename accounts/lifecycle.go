@@ -0,0 +1,127 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+)
+
+// Scheme determines the account status lifecycle, see Attributes.Status.
+type Scheme string
+
+const (
+	// Faster Payments: pending, confirmed or closed. Closed accounts must have a status reason.
+	SchemeFPS Scheme = "FPS"
+
+	// FPS Indirect via LHV: pending, confirmed or failed. Status reasons are not supported.
+	SchemeLHV Scheme = "LHV"
+
+	// SEPA: pending, confirmed or failed.
+	SchemeSEPA Scheme = "SEPA"
+
+	// All other services: pending or confirmed.
+	SchemeOther Scheme = "other"
+)
+
+var transitions = map[Scheme]map[Status][]Status{
+	SchemeFPS: {
+		StatusPending:   {StatusConfirmed, StatusClosed},
+		StatusConfirmed: {StatusClosed},
+	},
+	SchemeLHV: {
+		StatusPending: {StatusConfirmed, StatusFailed},
+	},
+	SchemeSEPA: {
+		StatusPending: {StatusConfirmed, StatusFailed},
+	},
+	SchemeOther: {
+		StatusPending: {StatusConfirmed},
+	},
+}
+
+// CheckTransition checks whether an account of given scheme can change status from one to another.
+// Keeping the current status is always legal. Status reason must be provided when an FPS account
+// is closed and can't be provided otherwise.
+//
+// When transition is illegal returns IllegalTransitionError
+func CheckTransition(scheme Scheme, from, to Status, reason *StatusReason) error {
+	allowed, ok := transitions[scheme]
+	if !ok {
+		return fmt.Errorf("unknown scheme: %q", scheme)
+	}
+	illegal := func(msg string) error {
+		return &IllegalTransitionError{Scheme: scheme, From: from, To: to, Reason: reason, Msg: msg}
+	}
+
+	if reason != nil && (scheme != SchemeFPS || to != StatusClosed) {
+		return illegal("status reason can be provided for closed FPS accounts only")
+	}
+	if scheme == SchemeFPS && to == StatusClosed && reason == nil {
+		return illegal("status reason is required to close FPS account")
+	}
+	if from == to {
+		return nil
+	}
+	for _, s := range allowed[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return illegal("transition not allowed")
+}
+
+// Lifecycle changes account status according to the rules of its scheme.
+// Illegal transitions are rejected before any update is sent.
+type Lifecycle struct {
+	svc    Service
+	scheme Scheme
+}
+
+func NewLifecycle(svc Service, scheme Scheme) *Lifecycle {
+	return &Lifecycle{svc: svc, scheme: scheme}
+}
+
+// Transition fetches the account to check its current status and updates it to the new one.
+// Accounts without status are treated as pending.
+//
+// When transition is illegal returns IllegalTransitionError
+// When account has other version than given returns InvalidVersionError
+// Otherwise returns errors of Service Fetch and Update.
+func (l *Lifecycle) Transition(ctx context.Context, id string, version int64, to Status, reason *StatusReason) (*Data, error) {
+	fetched, err := l.svc.Fetch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	acc := fetched.Data
+	if acc == nil || acc.Version == nil || *acc.Version != version {
+		return nil, &InvalidVersionError{Ver: version}
+	}
+	from := StatusPending
+	if acc.Attributes != nil && acc.Attributes.Status != nil {
+		from = *acc.Attributes.Status
+	}
+
+	if err := CheckTransition(l.scheme, from, to, reason); err != nil {
+		return nil, err
+	}
+
+	updated, err := l.svc.Update(ctx, id, version, &Attributes{Status: &to, StatusReason: reason})
+	if err != nil {
+		return nil, err
+	}
+	return updated.Data, nil
+}
+
+// Confirm changes account status to confirmed.
+func (l *Lifecycle) Confirm(ctx context.Context, id string, version int64) (*Data, error) {
+	return l.Transition(ctx, id, version, StatusConfirmed, nil)
+}
+
+// Close changes account status to closed with given reason. Only FPS accounts can be closed.
+func (l *Lifecycle) Close(ctx context.Context, id string, version int64, reason StatusReason) (*Data, error) {
+	return l.Transition(ctx, id, version, StatusClosed, &reason)
+}
+
+// Fail changes account status to failed. Only SEPA and LHV accounts can fail.
+func (l *Lifecycle) Fail(ctx context.Context, id string, version int64) (*Data, error) {
+	return l.Transition(ctx, id, version, StatusFailed, nil)
+}
@@ -0,0 +1,120 @@
+package accounts
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Accounts_CheckTransition(t *testing.T) {
+	reason := StatusReasonAccountClosed
+	tests := []struct {
+		scheme Scheme
+		from   Status
+		to     Status
+		reason *StatusReason
+		legal  bool
+	}{
+		{SchemeFPS, StatusPending, StatusConfirmed, nil, true},
+		{SchemeFPS, StatusConfirmed, StatusClosed, &reason, true},
+		{SchemeFPS, StatusPending, StatusClosed, &reason, true},
+		{SchemeFPS, StatusConfirmed, StatusClosed, nil, false},
+		{SchemeFPS, StatusPending, StatusConfirmed, &reason, false},
+		{SchemeFPS, StatusClosed, StatusConfirmed, nil, false},
+		{SchemeFPS, StatusPending, StatusFailed, nil, false},
+		{SchemeSEPA, StatusPending, StatusFailed, nil, true},
+		{SchemeSEPA, StatusConfirmed, StatusClosed, &reason, false},
+		{SchemeLHV, StatusPending, StatusConfirmed, nil, true},
+		{SchemeLHV, StatusFailed, StatusConfirmed, nil, false},
+		{SchemeOther, StatusPending, StatusConfirmed, nil, true},
+		{SchemeOther, StatusConfirmed, StatusConfirmed, nil, true},
+		{SchemeOther, StatusPending, StatusFailed, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scheme)+"/"+string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			// when
+			err := CheckTransition(tt.scheme, tt.from, tt.to, tt.reason)
+
+			// then
+			if tt.legal {
+				require.Empty(t, err)
+			} else {
+				require.IsType(t, &IllegalTransitionError{}, err, "Invalid error type")
+			}
+		})
+	}
+}
+
+func Test_Accounts_LifecycleCloseSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var patch string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 1, "attributes": {"status": "confirmed"}}}`), nil
+		}
+		b, _ := ioutil.ReadAll(req.Body)
+		patch = string(b)
+		return buildResponse(200, `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 2, "attributes": {"status": "closed", "status_reason": "account-closed"}}}`), nil
+	})
+	l := NewLifecycle(c, SchemeFPS)
+
+	// when
+	acc, err := l.Close(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1, StatusReasonAccountClosed)
+
+	// then
+	require.Empty(t, err)
+	require.JSONEq(t, `{
+						"data": {
+							"type": "accounts",
+							"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+							"version": 1,
+							"attributes": {
+								"status": "closed",
+								"status_reason": "account-closed"
+							}
+						}
+					}`, patch, "Invalid request body")
+	require.Equal(t, StatusClosed, *acc.Attributes.Status, "Invalid Status")
+	require.Equal(t, int64(2), *acc.Version, "Invalid Version")
+}
+
+func Test_Accounts_LifecycleFailed_IllegalTransition(t *testing.T) {
+	// given
+	ctx := context.Background()
+	updated := false
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 1, "attributes": {"status": "confirmed"}}}`), nil
+		}
+		updated = true
+		return buildResponse(200, `{}`), nil
+	})
+	l := NewLifecycle(c, SchemeSEPA)
+
+	// when
+	_, err := l.Fail(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1)
+
+	// then
+	require.IsType(t, &IllegalTransitionError{}, err, "Invalid error type")
+	require.Equal(t, StatusConfirmed, err.(*IllegalTransitionError).From)
+	require.Equal(t, StatusFailed, err.(*IllegalTransitionError).To)
+	require.False(t, updated, "Update should not be sent")
+}
+
+func Test_Accounts_LifecycleFailed_InvalidVersion(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(200, `{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 3}}`))
+	l := NewLifecycle(c, SchemeOther)
+
+	// when
+	_, err := l.Confirm(ctx, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1)
+
+	// then
+	require.IsType(t, &InvalidVersionError{}, err, "Invalid error type")
+	require.Equal(t, int64(1), err.(*InvalidVersionError).Ver)
+}
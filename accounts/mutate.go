@@ -0,0 +1,139 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/althink/form3/internal/transport"
+)
+
+const (
+	defaultMutateAttempts = 5
+	defaultMutateBase     = 50 * time.Millisecond
+	defaultMutateMax      = time.Second
+)
+
+type mutateConfig struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+type MutateOption func(*mutateConfig)
+
+// WithMutateAttempts sets the maximum number of fetch-modify-update rounds. Defaults to 5.
+func WithMutateAttempts(n int) MutateOption {
+	return func(c *mutateConfig) {
+		if n > 0 {
+			c.attempts = n
+		}
+	}
+}
+
+// WithMutateBackoff sets the delay bounds between rounds. The delay grows exponentially
+// from base up to max and is randomly jittered. Defaults to 50ms and 1s.
+func WithMutateBackoff(base, max time.Duration) MutateOption {
+	return func(c *mutateConfig) {
+		c.baseDelay = base
+		c.maxDelay = max
+	}
+}
+
+// Mutate fetches the account with given id, applies fn to it and writes it back with Update.
+// When the account was modified by someone else in the meantime (InvalidVersionError)
+// the whole round is repeated with a fresh copy of the account, so fn may be called several times.
+// Returns the account persisted by the successful Update.
+//
+// Fields can't be cleared, because empty attributes aren't sent with Update and the server keeps
+// their values. When fn clears a field that was set, e.g. sets Status to nil or Bic to "",
+// ClearedFieldsError is returned and nothing is updated.
+//
+// When fn returns an error, it's returned as is and nothing is updated.
+// When all attempts hit a version conflict, the last InvalidVersionError is returned.
+func Mutate(ctx context.Context, svc Service, id string, fn func(*Data) error, opts ...MutateOption) (*Data, error) {
+	cfg := mutateConfig{
+		attempts:  defaultMutateAttempts,
+		baseDelay: defaultMutateBase,
+		maxDelay:  defaultMutateMax,
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	// fully jittered, so concurrent writers of the same account spread out
+	backoff := transport.RetryPolicy{BaseDelay: cfg.baseDelay, MaxDelay: cfg.maxDelay, Jitter: 1}
+	var err error
+	for attempt := 0; attempt < cfg.attempts; attempt++ {
+		if attempt > 0 {
+			if err := transport.Sleep(ctx, backoff.Delay(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		var fetched *FetchSuccess
+		fetched, err = svc.Fetch(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		acc := fetched.Data
+		if acc == nil || acc.Version == nil {
+			return nil, fmt.Errorf("fetched account %s has no version", id)
+		}
+		ver := *acc.Version
+
+		var before interface{}
+		if err := roundTrip(acc.Attributes, &before); err != nil {
+			return nil, err
+		}
+		if err := fn(acc); err != nil {
+			return nil, err
+		}
+		if fields, err := clearedFields(before, acc.Attributes); err != nil {
+			return nil, err
+		} else if len(fields) > 0 {
+			return nil, &ClearedFieldsError{ID: id, Fields: fields}
+		}
+
+		var updated *UpdateSuccess
+		updated, err = svc.Update(ctx, id, ver, acc.Attributes)
+		if err == nil {
+			return updated.Data, nil
+		}
+		var verErr *InvalidVersionError
+		if !errors.As(err, &verErr) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// clearedFields returns JSON paths of attributes set before, but missing in the mutated ones.
+func clearedFields(before interface{}, mutated *Attributes) ([]string, error) {
+	var after interface{}
+	if err := roundTrip(mutated, &after); err != nil {
+		return nil, err
+	}
+	var fields []string
+	missing(before, after, "attributes", &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func missing(before, after interface{}, path string, fields *[]string) {
+	b, ok := before.(map[string]interface{})
+	if !ok {
+		return
+	}
+	a, _ := after.(map[string]interface{})
+	for k, v := range b {
+		p := fmt.Sprintf("%s.%s", path, k)
+		if _, ok := a[k]; !ok {
+			*fields = append(*fields, p)
+			continue
+		}
+		missing(v, a[k], p, fields)
+	}
+}
@@ -0,0 +1,164 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mutateFetchBody = `{
+							"data": {
+								"type": "accounts",
+								"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+								"version": 1,
+								"attributes": {
+									"country": "GB"
+								}
+							}
+						}`
+
+const mutateUpdateBody = `{
+							"data": {
+								"type": "accounts",
+								"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+								"version": 2,
+								"attributes": {
+									"country": "GB",
+									"customer_id": "customer-1"
+								}
+							}
+						}`
+
+func Test_Accounts_MutateSuccess_AfterConflict(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fetches, updates := 0, 0
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			fetches++
+			return buildResponse(200, mutateFetchBody), nil
+		}
+		updates++
+		if updates == 1 {
+			return buildResponse(409, ``), nil
+		}
+		return buildResponse(200, mutateUpdateBody), nil
+	})
+	calls := 0
+
+	// when
+	acc, err := Mutate(ctx, c, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", func(d *Data) error {
+		calls++
+		d.Attributes.CustomerID = "customer-1"
+		return nil
+	}, WithMutateBackoff(time.Millisecond, time.Millisecond))
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 2, fetches, "Invalid number of fetches")
+	require.Equal(t, 2, updates, "Invalid number of updates")
+	require.Equal(t, 2, calls, "Invalid number of mutations")
+	require.Equal(t, int64(2), *acc.Version, "Invalid Version")
+	require.Equal(t, "customer-1", acc.Attributes.CustomerID, "Invalid CustomerID")
+}
+
+func Test_Accounts_MutateFailed_AttemptsExhausted(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, mutateFetchBody), nil
+		}
+		return buildResponse(409, ``), nil
+	})
+	calls := 0
+
+	// when
+	_, err := Mutate(ctx, c, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", func(d *Data) error {
+		calls++
+		return nil
+	}, WithMutateAttempts(3), WithMutateBackoff(time.Millisecond, time.Millisecond))
+
+	// then
+	require.IsType(t, &InvalidVersionError{}, err, "Invalid error type")
+	require.Equal(t, int64(1), err.(*InvalidVersionError).Ver)
+	require.Equal(t, 3, calls, "Invalid number of mutations")
+}
+
+func Test_Accounts_MutateFailed_MutationError(t *testing.T) {
+	// given
+	ctx := context.Background()
+	updates := 0
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, mutateFetchBody), nil
+		}
+		updates++
+		return buildResponse(200, mutateUpdateBody), nil
+	})
+	mutationErr := errors.New("mutation failed")
+
+	// when
+	_, err := Mutate(ctx, c, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", func(d *Data) error {
+		return mutationErr
+	})
+
+	// then
+	require.Equal(t, mutationErr, err)
+	require.Equal(t, 0, updates, "Invalid number of updates")
+}
+
+func Test_Accounts_MutateFailed_ClearedFields(t *testing.T) {
+	// given
+	ctx := context.Background()
+	updates := 0
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			return buildResponse(200, `{
+							"data": {
+								"type": "accounts",
+								"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+								"version": 1,
+								"attributes": {
+									"country": "GB",
+									"status": "confirmed",
+									"secondary_identification": "A1B2C3D4"
+								}
+							}
+						}`), nil
+		}
+		updates++
+		return buildResponse(200, mutateUpdateBody), nil
+	})
+
+	// when
+	_, err := Mutate(ctx, c, "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", func(d *Data) error {
+		d.Attributes.Status = nil
+		d.Attributes.SecondaryIdentification = nil
+		d.Attributes.CustomerID = "customer-1"
+		return nil
+	})
+
+	// then
+	require.IsType(t, &ClearedFieldsError{}, err, "Invalid error type")
+	require.Equal(t, []string{"attributes.secondary_identification", "attributes.status"}, err.(*ClearedFieldsError).Fields)
+	require.Equal(t, 0, updates, "Account should not be updated")
+}
+
+func Test_Accounts_MutateFailed_UnknownAccount(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(404, ``))
+
+	// when
+	_, err := Mutate(ctx, c, "eb89cce1-3b1f-4b37-967f-23354c5ad61e", func(d *Data) error {
+		return nil
+	})
+
+	// then
+	require.IsType(t, &AccountNotFoundError{}, err, "Invalid error type")
+}
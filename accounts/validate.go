@@ -0,0 +1,161 @@
+package accounts
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/althink/form3/referencemask"
+)
+
+// Country specific rules of account attributes.
+// See https://api-docs.form3.tech/api.html#organisation-accounts-create for the source table.
+type countryRule struct {
+	bankIDRequired bool
+	// bankID is nil when bank_id is not supported in given country.
+	bankID *regexp.Regexp
+	// bankIDCode is empty when bank_id_code is not supported in given country.
+	bankIDCode    BankIDCode
+	bicRequired   bool
+	accountNumber *regexp.Regexp
+	ibanSupported bool
+	bankIDFormat  string
+	accountFormat string
+}
+
+func rule(bankIDRequired bool, bankIDFormat string, bankIDCode BankIDCode, bicRequired bool, accountFormat string, ibanSupported bool) countryRule {
+	r := countryRule{
+		bankIDRequired: bankIDRequired,
+		bankIDCode:     bankIDCode,
+		bicRequired:    bicRequired,
+		ibanSupported:  ibanSupported,
+		bankIDFormat:   bankIDFormat,
+		accountFormat:  accountFormat,
+	}
+	if bankIDFormat != "" {
+		r.bankID = regexp.MustCompile("^" + bankIDFormat + "$")
+	}
+	if accountFormat != "" {
+		r.accountNumber = regexp.MustCompile("^" + accountFormat + "$")
+	}
+	return r
+}
+
+var countryRules = map[string]countryRule{
+	"GB": rule(true, "[0-9]{6}", BankIDCodeGB, true, "[0-9]{8}", true),
+	"AU": rule(false, "[0-9]{6}", BankIDCodeAU, true, "[1-9][0-9]{5,9}", false),
+	"BE": rule(true, "[0-9]{3}", BankIDCodeBE, false, "[0-9]{7}", true),
+	"CA": rule(false, "0[0-9]{8}", BankIDCodeCA, true, "[0-9]{7,12}", false),
+	"FR": rule(true, "[0-9A-Z]{10}", BankIDCodeFR, false, "[0-9A-Z]{10}", true),
+	"DE": rule(true, "[0-9]{8}", BankIDCodeDE, false, "[0-9]{7}", true),
+	"GR": rule(true, "[0-9]{7}", BankIDCodeGR, false, "[0-9]{16}", true),
+	"HK": rule(false, "[0-9]{3}", BankIDCodeHK, true, "[0-9]{9,12}", false),
+	"IT": rule(true, "[0-9A-Z]{10,11}", BankIDCodeIT, false, "[0-9A-Z]{12}", true),
+	"LU": rule(true, "[0-9]{3}", BankIDCodeLU, false, "[0-9A-Z]{13}", true),
+	"NL": rule(false, "", "", true, "[0-9]{10}", true),
+	"PL": rule(true, "[0-9]{8}", BankIDCodePL, false, "[0-9]{16}", true),
+	"PT": rule(true, "[0-9]{8}", BankIDCodePT, false, "[0-9]{11}", true),
+	"ES": rule(true, "[0-9]{8}", BankIDCodeES, false, "[0-9]{10}", true),
+	"CH": rule(true, "[0-9]{5}", BankIDCodeCH, false, "[0-9A-Z]{12}", true),
+	"US": rule(true, "[0-9]{9}", BankIDCodeUS, true, "[0-9]{6,17}", false),
+}
+
+var countryFormat = regexp.MustCompile("^[A-Z]{2}$")
+
+// Validate checks account data against Form3 rules before it's sent to the server.
+// Besides required fields it checks country specific rules for bank_id, bank_id_code,
+// bic and account_number. The bic is checked for ISO 9362 structure and its country
+// must agree with the account country. Countries without known rules are checked only for required fields.
+//
+// All problems found are returned at once in ValidationError.
+func Validate(account *Data) error {
+	v := &ValidationError{}
+	if account == nil {
+		v.add("data", "is required")
+		return v
+	}
+	if account.ID == "" {
+		v.add("id", "is required")
+	}
+	if account.OrganisationID == "" {
+		v.add("organisation_id", "is required")
+	}
+	if account.Type != "" && account.Type != Type {
+		v.add("type", fmt.Sprintf("must be %q", Type))
+	}
+
+	a := account.Attributes
+	if a == nil {
+		v.add("attributes", "is required")
+		return v
+	}
+	validateAttributes(a, v)
+
+	if len(v.Fields) > 0 {
+		return v
+	}
+	return nil
+}
+
+func validateAttributes(a *Attributes, v *ValidationError) {
+	if a.Country == "" {
+		v.add("attributes.country", "is required")
+		return
+	}
+	if !countryFormat.MatchString(a.Country) {
+		v.add("attributes.country", "must be ISO 3166-1 alpha-2 code")
+		return
+	}
+	if len(a.Name) > 4 {
+		v.add("attributes.name", "can have up to four lines")
+	}
+
+	if a.Bic != "" {
+		b, err := ParseBIC(a.Bic)
+		if err != nil {
+			v.add("attributes.bic", err.(*InvalidBICError).Reason)
+		} else if !b.MatchesCountry(a.Country) {
+			v.add("attributes.bic", fmt.Sprintf("country %s does not match account country %s", b.Country, a.Country))
+		}
+	}
+
+	if a.ReferenceMask != nil {
+		if _, err := referencemask.Parse(*a.ReferenceMask); err != nil {
+			v.add("attributes.reference_mask", err.(*referencemask.SyntaxError).Msg)
+		}
+	}
+
+	r, ok := countryRules[a.Country]
+	if !ok {
+		return
+	}
+
+	switch {
+	case r.bankID == nil && a.BankID != "":
+		v.add("attributes.bank_id", fmt.Sprintf("is not supported for %s", a.Country))
+	case a.BankID == "" && r.bankIDRequired:
+		v.add("attributes.bank_id", fmt.Sprintf("is required for %s", a.Country))
+	case a.BankID != "" && !r.bankID.MatchString(a.BankID):
+		v.add("attributes.bank_id", fmt.Sprintf("must match %s for %s", r.bankIDFormat, a.Country))
+	}
+
+	switch {
+	case r.bankIDCode == "" && a.BankIDCode != "":
+		v.add("attributes.bank_id_code", fmt.Sprintf("is not supported for %s", a.Country))
+	case r.bankIDCode != "" && a.BankIDCode == "" && a.BankID != "":
+		v.add("attributes.bank_id_code", fmt.Sprintf("is required for %s", a.Country))
+	case r.bankIDCode != "" && a.BankIDCode != "" && a.BankIDCode != r.bankIDCode:
+		v.add("attributes.bank_id_code", fmt.Sprintf("must be %s for %s", r.bankIDCode, a.Country))
+	}
+
+	if r.bicRequired && a.Bic == "" {
+		v.add("attributes.bic", fmt.Sprintf("is required for %s", a.Country))
+	}
+
+	if a.AccountNumber != "" && !r.accountNumber.MatchString(a.AccountNumber) {
+		v.add("attributes.account_number", fmt.Sprintf("must match %s for %s", r.accountFormat, a.Country))
+	}
+
+	if a.Iban != "" && !r.ibanSupported {
+		v.add("attributes.iban", fmt.Sprintf("is not supported for %s", a.Country))
+	}
+}
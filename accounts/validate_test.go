@@ -0,0 +1,126 @@
+package accounts
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Accounts_ValidateSuccess(t *testing.T) {
+	tests := map[string]*Attributes{
+		"GB":              {Country: "GB", BankID: "400300", BankIDCode: "GBDSC", Bic: "NWBKGB22", AccountNumber: "41426819"},
+		"DE":              {Country: "DE", BankID: "37040044", BankIDCode: "DEBLZ"},
+		"FR":              {Country: "FR", BankID: "2004101005", BankIDCode: "FR", AccountNumber: "0500013M02"},
+		"NL":              {Country: "NL", Bic: "ABNANL2A"},
+		"PL":              {Country: "PL", BankID: "10901014", BankIDCode: "PLKNR", Name: []string{"John Smith"}},
+		"unknown country": {Country: "SE"},
+	}
+	for name, attrs := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			err := Validate(New("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", attrs))
+
+			// then
+			require.Empty(t, err)
+		})
+	}
+}
+
+func Test_Accounts_ValidateFailed(t *testing.T) {
+	tests := map[string]struct {
+		attrs  *Attributes
+		fields []string
+	}{
+		"missing country": {
+			attrs:  &Attributes{},
+			fields: []string{"attributes.country"},
+		},
+		"GB missing bank id and bic": {
+			attrs:  &Attributes{Country: "GB"},
+			fields: []string{"attributes.bank_id", "attributes.bic"},
+		},
+		"GB invalid sort code and account number": {
+			attrs:  &Attributes{Country: "GB", BankID: "4003", BankIDCode: "GBDSC", Bic: "NWBKGB22", AccountNumber: "123"},
+			fields: []string{"attributes.bank_id", "attributes.account_number"},
+		},
+		"DE invalid bank id code": {
+			attrs:  &Attributes{Country: "DE", BankID: "37040044", BankIDCode: "GBDSC"},
+			fields: []string{"attributes.bank_id_code"},
+		},
+		"DE missing bank id code": {
+			attrs:  &Attributes{Country: "DE", BankID: "37040044"},
+			fields: []string{"attributes.bank_id_code"},
+		},
+		"FR too short bank id": {
+			attrs:  &Attributes{Country: "FR", BankID: "20041", BankIDCode: "FR"},
+			fields: []string{"attributes.bank_id"},
+		},
+		"NL bank id not supported": {
+			attrs:  &Attributes{Country: "NL", BankID: "ABNA", Bic: "ABNANL2A"},
+			fields: []string{"attributes.bank_id"},
+		},
+		"GB invalid bic": {
+			attrs:  &Attributes{Country: "GB", BankID: "400300", BankIDCode: "GBDSC", Bic: "NWBK-22"},
+			fields: []string{"attributes.bic"},
+		},
+		"DE bic of other country": {
+			attrs:  &Attributes{Country: "DE", BankID: "37040044", BankIDCode: "DEBLZ", Bic: "NWBKGB22"},
+			fields: []string{"attributes.bic"},
+		},
+		"invalid reference mask": {
+			attrs:  &Attributes{Country: "SE", ReferenceMask: strPtr(`###\`)},
+			fields: []string{"attributes.reference_mask"},
+		},
+		"US iban not supported": {
+			attrs:  &Attributes{Country: "US", BankID: "021000021", BankIDCode: "USABA", Bic: "CHASUS33", Iban: "US00"},
+			fields: []string{"attributes.iban"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			err := Validate(New("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", tt.attrs))
+
+			// then
+			require.IsType(t, &ValidationError{}, err, "Invalid error type")
+			var fields []string
+			for _, f := range err.(*ValidationError).Fields {
+				fields = append(fields, f.Field)
+			}
+			require.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func Test_Accounts_ValidateFailed_MissingIDs(t *testing.T) {
+	// when
+	err := Validate(&Data{Attributes: &Attributes{Country: "SE"}})
+
+	// then
+	require.IsType(t, &ValidationError{}, err, "Invalid error type")
+	require.Len(t, err.(*ValidationError).Fields, 2)
+	require.Equal(t, "invalid account: id is required; organisation_id is required", err.Error())
+}
+
+func Test_Accounts_CreateFailed_Validation(t *testing.T) {
+	// given
+	ctx := context.Background()
+	called := false
+	c := setUpMockClient(func(*http.Request) (*http.Response, error) {
+		called = true
+		return buildResponse(201, `{}`), nil
+	}, WithValidation())
+
+	// when
+	_, err := c.Create(ctx, NewWithGenID("eb0bd6f5-c3f5-44b2-b677-acd23cdde73c", &Attributes{Country: "GB"}))
+
+	// then
+	require.IsType(t, &ValidationError{}, err, "Invalid error type")
+	require.False(t, called, "Request should not be sent")
+}
+
+func strPtr(val string) *string {
+	return &val
+}
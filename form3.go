@@ -1,21 +1,67 @@
 package form3
 
 import (
+	"crypto"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/althink/form3/accounts"
+	"github.com/althink/form3/httpsig"
+	"github.com/althink/form3/internal/transport"
+	"github.com/althink/form3/payments"
 )
 
 var defaultUrl string = "http://localhost:8080/v1/"
 
 type Form3 struct {
 	Accounts accounts.Service
+	Payments payments.Service
 
 	baseURL    url.URL
 	httpClient *http.Client
+	accounts   []accounts.ClientOption
+	transport  []transport.Option
+	err        error
+
+	credentials *clientCredentials
+}
+
+type clientCredentials struct {
+	clientID string
+	secret   string
+	tokenURL string
+}
+
+// TokenError is returned when an OAuth2 access token couldn't be obtained, see WithClientCredentials.
+type TokenError = transport.TokenError
+
+// Operation describes a call of a service, e.g. Create of accounts, see WithMiddleware.
+type Operation = transport.Operation
+
+// Invoker performs the operation and returns its typed result, e.g. *accounts.CreateSuccess,
+// and typed error, e.g. *accounts.AccountNotFoundError.
+type Invoker = transport.Invoker
+
+// Middleware wraps invocations of all operations, see WithMiddleware.
+type Middleware = transport.Middleware
+
+// Logger is the subset of *slog.Logger used by the client, see WithLogger.
+type Logger = transport.Logger
+
+// RetryPolicy controls retries of failed calls, see WithRetryPolicy.
+type RetryPolicy = transport.RetryPolicy
+
+// DefaultRetryPolicy returns a policy with 3 attempts and jittered delays from 100ms up to 2s.
+func DefaultRetryPolicy() RetryPolicy {
+	return transport.DefaultRetryPolicy()
+}
+
+// DefaultRetryable retries transport errors, like connection resets, except context
+// cancellation, and 429, 500, 502, 503 and 504 responses.
+func DefaultRetryable(resp *http.Response, err error) bool {
+	return transport.DefaultRetryable(resp, err)
 }
 
 type Option func(*Form3)
@@ -32,6 +78,119 @@ func WithBaseURL(u url.URL) Option {
 	}
 }
 
+// WithRetryPolicy enables retries of failed calls.
+// Fetch, List and Delete calls are always retried. Create is retried as well, because
+// account IDs are generated on the client side: when a retried Create finds the account
+// already existing, the account created by the previous attempt is returned, after checking
+// it's equivalent to the requested one, see WithIdempotentCreate.
+// Updates, and creation of payments and their submissions, are never retried; payment reads are retried like account reads.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(f3 *Form3) {
+		f3.transport = append(f3.transport, transport.WithRetryPolicy(p))
+	}
+}
+
+// WithRateLimit shapes outbound traffic of all services of the client with a token bucket,
+// allowing rps requests per second on average and bursts of up to burst requests.
+// Calls that couldn't be sent before their context deadline fail immediately.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(f3 *Form3) {
+		f3.transport = append(f3.transport, transport.WithLimiter(transport.NewLimiter(rps, burst)))
+	}
+}
+
+// WithRateLimitWait makes services wait and resend calls rejected with 429 status
+// for as long as the server asks with Retry-After or X-RateLimit-Reset headers.
+// Calls are resent only when the wait fits before the context deadline,
+// otherwise RateLimitedError is returned. Use context deadlines to bound the total wait.
+// Without a deadline, a call is resent this way at most 5 times and waits at most 2 minutes in total,
+// then the retry policy applies to the 429 response.
+func WithRateLimitWait() Option {
+	return func(f3 *Form3) {
+		f3.transport = append(f3.transport, transport.WithRateLimitWait())
+	}
+}
+
+// WithSigner signs all requests with given RSA or ECDSA private key, as required by Form3 API.
+// Requests get a SHA-256 Digest header of the body and a Signature header over
+// (request-target), host, date, digest and content-type, see package httpsig.
+// NewClient fails when the key type is not supported.
+func WithSigner(keyID string, key crypto.Signer) Option {
+	return func(f3 *Form3) {
+		s, err := httpsig.NewSigner(keyID, key)
+		if err != nil {
+			f3.err = fmt.Errorf("could not create signer: %w", err)
+			return
+		}
+		f3.transport = append(f3.transport, transport.WithSigner(s))
+	}
+}
+
+// WithClientCredentials authenticates all requests with OAuth2 bearer tokens obtained
+// with the client credentials grant from the token endpoint, e.g. "https://api.form3.tech/v1/oauth2/token".
+// Relative token URLs, like "oauth2/token", are resolved against the base URL.
+// Tokens are cached and shared by all services until shortly before they expire.
+// Calls rejected with 401 status are sent once more with a fresh token.
+// When a token can't be obtained calls return TokenError.
+func WithClientCredentials(clientID, secret, tokenURL string) Option {
+	return func(f3 *Form3) {
+		f3.credentials = &clientCredentials{clientID: clientID, secret: secret, tokenURL: tokenURL}
+	}
+}
+
+// WithMiddleware wraps every operation of all services with given middlewares, the first one
+// being the outermost. Middlewares get the operation descriptor and the request, and can observe
+// or change the request and the typed result, or short-circuit the call, which makes them
+// suitable for auditing, policy checks or test fakes. Unlike http.Client transports, they run
+// once per operation, around retries. Client side validation happens before middlewares are called.
+func WithMiddleware(m ...Middleware) Option {
+	return func(f3 *Form3) {
+		f3.transport = append(f3.transport, transport.WithMiddleware(m...))
+	}
+}
+
+// WithLogger logs every attempt of every call: method, path, status, latency and attempt number,
+// at info level, or warn level for failures. *slog.Logger can be used directly.
+// Bodies are not logged unless WithBodyLogging is used.
+func WithLogger(l Logger) Option {
+	return func(f3 *Form3) {
+		f3.transport = append(f3.transport, transport.WithLogger(l))
+	}
+}
+
+// WithBodyLogging adds request and response bodies to logs of WithLogger. Values of given JSON fields
+// are masked. When no fields are given, personal data of accounts and payments is masked: iban, account_number,
+// account_name, name, alternative_names, address and secondary_identification.
+func WithBodyLogging(redactFields ...string) Option {
+	return func(f3 *Form3) {
+		f3.transport = append(f3.transport, transport.WithBodyLogging(redactFields...))
+	}
+}
+
+// WithIdempotentCreate makes Create calls idempotent: when an account with the same ID already exists,
+// it's fetched and returned if it's equivalent to the requested one, so timed out calls can be safely
+// repeated. Otherwise accounts.IdempotencyMismatchError is returned.
+func WithIdempotentCreate() Option {
+	return func(f3 *Form3) {
+		f3.accounts = append(f3.accounts, accounts.WithIdempotentCreate())
+	}
+}
+
+// WithValidation makes services validate resources on the client side before they are created.
+func WithValidation() Option {
+	return func(f3 *Form3) {
+		f3.accounts = append(f3.accounts, accounts.WithValidation())
+	}
+}
+
+// WithStrictEnums makes services reject unknown values of enumerated fields,
+// instead of passing them through. Writes are checked before they are sent, see accounts.WithStrictEnums.
+func WithStrictEnums() Option {
+	return func(f3 *Form3) {
+		f3.accounts = append(f3.accounts, accounts.WithStrictEnums())
+	}
+}
+
 // NewClient creates new Form3 client.
 func NewClient(opts ...Option) (*Form3, error) {
 	url, err := url.Parse(defaultUrl)
@@ -47,12 +206,26 @@ func NewClient(opts ...Option) (*Form3, error) {
 	for _, o := range opts {
 		o(f3)
 	}
+	if f3.err != nil {
+		return nil, f3.err
+	}
 
 	if !strings.HasSuffix(f3.baseURL.Path, "/") {
 		return nil, fmt.Errorf("BaseURL must have a trailing slash: %q", f3.baseURL.String())
 	}
 
-	f3.Accounts = accounts.NewClient(f3.httpClient, f3.baseURL)
+	if c := f3.credentials; c != nil {
+		tokenURL, err := f3.baseURL.Parse(c.tokenURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse token URL: %w", err)
+		}
+		tokens := transport.NewTokenSource(f3.httpClient, tokenURL.String(), c.clientID, c.secret)
+		f3.transport = append(f3.transport, transport.WithTokenSource(tokens))
+	}
+
+	accountsOpts := append([]accounts.ClientOption{transport.Configure(f3.transport...)}, f3.accounts...)
+	f3.Accounts = accounts.NewClient(f3.httpClient, f3.baseURL, accountsOpts...)
+	f3.Payments = payments.NewClient(f3.httpClient, f3.baseURL, transport.Configure(f3.transport...))
 
 	return f3, nil
 }
@@ -4,24 +4,32 @@ package form3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/althink/form3/accounts"
+	"github.com/althink/form3/form3test/cassette"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
+// Directory of cassettes recorded with FORM3_CASSETTES=record and replayed offline with FORM3_CASSETTES=replay.
+const cassettesDir = "testdata/cassettes"
+
 func TestMain(t *testing.M) {
-	err := waitUntilHealthy(60 * time.Second)
-	if err != nil {
-		log.Fatal("Skipping tests: ", err)
+	if cassetteMode() != cassette.ModeReplay {
+		err := waitUntilHealthy(60 * time.Second)
+		if err != nil {
+			log.Fatal("Skipping tests: ", err)
+		}
 	}
 
 	t.Run()
@@ -29,7 +37,7 @@ func TestMain(t *testing.M) {
 
 func TestIntegration_Accounts_CreateFetchAndDeleteSuccess(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	orgID := uuid.New().String()
 	acc := accounts.NewWithGenID(orgID,
@@ -43,12 +51,12 @@ func TestIntegration_Accounts_CreateFetchAndDeleteSuccess(t *testing.T) {
 			Iban:                    "GB11NWBK40030041426819",
 			Name:                    []string{"Samantha Holder"},
 			AlternativeNames:        []string{"Sam Holder"},
-			AccountClassification:   strPtr("Personal"),
+			AccountClassification:   classificationPtr(accounts.ClassificationPersonal),
 			JointAccount:            boolPtr(false),
 			AccountMatchingOptOut:   boolPtr(false),
 			SecondaryIdentification: strPtr("A1B2C3D4"),
 			Switched:                boolPtr(false),
-			Status:                  strPtr("confirmed"),
+			Status:                  statusPtr(accounts.StatusConfirmed),
 		})
 
 	// when
@@ -94,7 +102,7 @@ func TestIntegration_Accounts_CreateFetchAndDeleteSuccess(t *testing.T) {
 
 func TestIntegration_Accounts_CreateFailed_InvalidData(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 
 	// when
@@ -107,7 +115,7 @@ func TestIntegration_Accounts_CreateFailed_InvalidData(t *testing.T) {
 
 func TestIntegration_Accounts_CreateFailed_AlreadyExists(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	accID := uuid.New().String()
 	orgID := uuid.New().String()
@@ -126,7 +134,7 @@ func TestIntegration_Accounts_CreateFailed_AlreadyExists(t *testing.T) {
 
 func TestIntegration_Accounts_FetchFailed_InvalidData(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	accID := "invalid"
 
@@ -140,7 +148,7 @@ func TestIntegration_Accounts_FetchFailed_InvalidData(t *testing.T) {
 
 func TestIntegration_Accounts_FetchFailed_UnknownAccount(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	accID := "6acb52e8-375b-453e-a3a9-9110c9aca283"
 
@@ -154,7 +162,7 @@ func TestIntegration_Accounts_FetchFailed_UnknownAccount(t *testing.T) {
 
 func TestIntegration_Accounts_DeleteFailed_UnknownAccount(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	accID := "6acb52e8-375b-453e-a3a9-9110c9aca283"
 
@@ -168,7 +176,7 @@ func TestIntegration_Accounts_DeleteFailed_UnknownAccount(t *testing.T) {
 
 func TestIntegration_Accounts_DeleteFailed_InvalidVersion(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	accID := uuid.New().String()
 	invalidVer := int64(7)
@@ -184,7 +192,7 @@ func TestIntegration_Accounts_DeleteFailed_InvalidVersion(t *testing.T) {
 
 func TestIntegration_Accounts_DeleteFailed_InvalidData(t *testing.T) {
 	// given
-	c := setUpClient()
+	c := setUpClient(t)
 	ctx := context.Background()
 	accID := "invalid"
 
@@ -196,18 +204,38 @@ func TestIntegration_Accounts_DeleteFailed_InvalidData(t *testing.T) {
 	require.NotEmpty(t, err.(*accounts.InvalidDataError).Msg)
 }
 
-func setUpClient() *Form3 {
+func setUpClient(t *testing.T) *Form3 {
 	u, err := url.Parse(resolveApiUrl())
 	if err != nil {
 		log.Fatal(err)
 	}
-	f3, err := NewClient(WithBaseURL(*u), WithHTTPClient(http.DefaultClient))
+	httpClient := http.DefaultClient
+	if mode := cassetteMode(); mode != cassette.ModeDisabled {
+		path := filepath.Join(cassettesDir, t.Name()+".json")
+		c, err := cassette.Load(path, mode, nil)
+		if errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Cassette %s is not recorded, record it with FORM3_CASSETTES=record docker-compose up", path)
+		} else if err != nil {
+			t.Fatalf("Failed to load cassette: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := c.Save(); err != nil {
+				t.Errorf("Failed to save cassette: %v", err)
+			}
+		})
+		httpClient = c.Client()
+	}
+	f3, err := NewClient(WithBaseURL(*u), WithHTTPClient(httpClient))
 	if err != nil {
 		log.Fatal(err)
 	}
 	return f3
 }
 
+func cassetteMode() cassette.Mode {
+	return cassette.Mode(os.Getenv("FORM3_CASSETTES"))
+}
+
 func resolveApiUrl() string {
 	host := os.Getenv("FORM3_HOST")
 	if host == "" {
@@ -256,3 +284,11 @@ func strPtr(val string) *string {
 func boolPtr(val bool) *bool {
 	return &val
 }
+
+func statusPtr(val accounts.Status) *accounts.Status {
+	return &val
+}
+
+func classificationPtr(val accounts.AccountClassification) *accounts.AccountClassification {
+	return &val
+}
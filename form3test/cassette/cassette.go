@@ -0,0 +1,311 @@
+// Package cassette records HTTP interactions with Form3 API to files and replays them offline.
+//
+// In record mode requests are sent to the real server and interactions are kept, to be saved
+// to a cassette file. In replay mode responses are served from the cassette without network.
+//
+// Requests are matched by method, path with query and JSON body. Headers, like Date, are not matched.
+// UUIDs, like IDs generated by accounts.NewWithGenID, are replaced with placeholders in order of
+// their first appearance, so a replayed test generating other IDs matches the recorded interactions,
+// and gets responses with its own IDs.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type Mode string
+
+const (
+	// Requests are sent to the server, nothing is recorded.
+	ModeDisabled Mode = ""
+	ModeRecord   Mode = "record"
+	ModeReplay   Mode = "replay"
+)
+
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+// Response headers not recorded, because they change on every call or are recomputed.
+var volatileHeaders = []string{"Date", "Content-Length", "Set-Cookie", "X-Request-Id"}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	// Path with query, host isn't recorded
+	URL  string `json:"url"`
+	Body string `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is a http.RoundTripper recording or replaying interactions. It's safe for concurrent use.
+type Cassette struct {
+	path string
+	mode Mode
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	ids          *placeholders
+}
+
+// Load creates a cassette stored in given file. In replay mode the file is read and must exist.
+// Requests are sent with next, or http.DefaultTransport when nil, unless they are replayed.
+func Load(path string, mode Mode, next http.RoundTripper) (*Cassette, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c := &Cassette{path: path, mode: mode, next: next, ids: newPlaceholders()}
+	if mode != ModeReplay {
+		return c, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read cassette: %w", err)
+	}
+	var file struct {
+		Interactions []*Interaction `json:"interactions"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("could not parse cassette %s: %w", path, err)
+	}
+	c.interactions = file.Interactions
+	c.used = make([]bool, len(file.Interactions))
+	return c, nil
+}
+
+// Client returns HTTP client using the cassette.
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Interactions returns recorded or loaded interactions.
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Save writes recorded interactions to the cassette file, creating its directory.
+// It does nothing unless the cassette records.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	b, err := json.MarshalIndent(map[string]interface{}{"interactions": c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(b, '\n'), 0644)
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch c.mode {
+	case ModeRecord:
+		return c.record(req)
+	case ModeReplay:
+		return c.replay(req)
+	}
+	return c.next.RoundTrip(req)
+}
+
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, h := range volatileHeaders {
+		header.Del(h)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    c.ids.normalize(req.URL.RequestURI()),
+			Body:   c.ids.normalize(canonical(body)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     normalizeHeader(header, c.ids),
+			Body:       c.ids.normalize(canonical(respBody)),
+		},
+	})
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// placeholders of new IDs are assigned only when an interaction matches
+	ids := c.ids.clone()
+	url := ids.normalize(req.URL.RequestURI())
+	reqBody := ids.normalize(canonical(body))
+	for i, in := range c.interactions {
+		if c.used[i] || in.Request.Method != req.Method || in.Request.URL != url || in.Request.Body != reqBody {
+			continue
+		}
+		c.used[i] = true
+		c.ids = ids
+
+		header := http.Header{}
+		for k, v := range in.Response.Header {
+			for _, s := range v {
+				header.Add(k, ids.denormalize(s))
+			}
+		}
+		respBody := ids.denormalize(in.Response.Body)
+		header.Set("Content-Length", strconv.Itoa(len(respBody)))
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s %s", ErrNoInteraction, req.Method, url, reqBody)
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// canonical returns JSON with sorted keys and no insignificant whitespace, or the body as it is.
+func canonical(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return string(body)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+func normalizeHeader(h http.Header, ids *placeholders) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	res := http.Header{}
+	for k, v := range h {
+		for _, s := range v {
+			res.Add(k, ids.normalize(s))
+		}
+	}
+	return res
+}
+
+var uuidPattern = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// placeholders maps UUIDs to placeholders numbered in order of their first appearance.
+// Placeholders are valid UUIDs too, so recorded bodies still pass UUID validation.
+type placeholders struct {
+	toPlaceholder   map[string]string
+	fromPlaceholder map[string]string
+}
+
+func newPlaceholders() *placeholders {
+	return &placeholders{toPlaceholder: map[string]string{}, fromPlaceholder: map[string]string{}}
+}
+
+func (p *placeholders) clone() *placeholders {
+	c := newPlaceholders()
+	for k, v := range p.toPlaceholder {
+		c.toPlaceholder[k] = v
+	}
+	for k, v := range p.fromPlaceholder {
+		c.fromPlaceholder[k] = v
+	}
+	return c
+}
+
+func (p *placeholders) normalize(s string) string {
+	return uuidPattern.ReplaceAllStringFunc(s, func(id string) string {
+		id = strings.ToLower(id)
+		ph, ok := p.toPlaceholder[id]
+		if !ok {
+			ph = fmt.Sprintf("00000000-0000-4000-8000-%012d", len(p.toPlaceholder)+1)
+			p.toPlaceholder[id] = ph
+			p.fromPlaceholder[ph] = id
+		}
+		return ph
+	})
+}
+
+// denormalize replaces placeholders with UUIDs they stand for. Placeholders not seen
+// in replayed requests, like IDs generated by the server, get new random UUIDs.
+func (p *placeholders) denormalize(s string) string {
+	return uuidPattern.ReplaceAllStringFunc(s, func(ph string) string {
+		id, ok := p.fromPlaceholder[ph]
+		if !ok {
+			id = uuid.New().String()
+			p.fromPlaceholder[ph] = id
+			p.toPlaceholder[id] = ph
+		}
+		return id
+	})
+}
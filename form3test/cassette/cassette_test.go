@@ -0,0 +1,88 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/althink/form3"
+	"github.com/althink/form3/accounts"
+	"github.com/althink/form3/form3test"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Cassette_RecordAndReplay(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	fake := form3test.NewServer()
+	u := fake.URL()
+
+	recorder, err := Load(path, ModeRecord, nil)
+	require.Empty(t, err)
+	scenario(t, client(t, recorder, u))
+	require.Empty(t, recorder.Save())
+	fake.Close()
+
+	// when
+	player, err := Load(path, ModeReplay, nil)
+	require.Empty(t, err)
+	scenario(t, client(t, player, u))
+
+	// then
+	require.Len(t, player.Interactions(), 4)
+	require.NotContains(t, player.Interactions()[0].Response.Header, "Date")
+}
+
+func Test_Cassette_ReplayFailed_NoInteraction(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	fake := form3test.NewServer()
+	defer fake.Close()
+	u := fake.URL()
+	recorder, _ := Load(path, ModeRecord, nil)
+	client(t, recorder, u).Accounts.Fetch(context.Background(), uuid.New().String())
+	require.Empty(t, recorder.Save())
+	player, _ := Load(path, ModeReplay, nil)
+
+	// when
+	_, err := client(t, player, u).Accounts.List(context.Background(), accounts.ListOptions{})
+
+	// then
+	require.True(t, errors.Is(err, ErrNoInteraction), "Invalid error: %v", err)
+}
+
+func Test_Cassette_LoadFailed_Missing(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil)
+	require.Error(t, err)
+}
+
+// scenario creates, fetches, lists and deletes an account with newly generated IDs.
+func scenario(t *testing.T, f3 *form3.Form3) {
+	ctx := context.Background()
+	acc := accounts.NewWithGenID(uuid.New().String(), &accounts.Attributes{Country: "GB", Name: []string{"Samantha Holder"}})
+
+	created, err := f3.Accounts.Create(ctx, acc)
+	require.Empty(t, err)
+	require.Equal(t, acc.ID, created.Data.ID)
+	require.Equal(t, acc.OrganisationID, created.Data.OrganisationID)
+
+	fetched, err := f3.Accounts.Fetch(ctx, acc.ID)
+	require.Empty(t, err)
+	require.Equal(t, acc.ID, fetched.Data.ID)
+
+	list, err := f3.Accounts.List(ctx, accounts.ListOptions{})
+	require.Empty(t, err)
+	require.Len(t, list.Data, 1)
+	require.Equal(t, acc.ID, list.Data[0].ID)
+
+	require.Empty(t, f3.Accounts.Delete(ctx, acc.ID, 0))
+}
+
+func client(t *testing.T, c *Cassette, u url.URL) *form3.Form3 {
+	f3, err := form3.NewClient(form3.WithBaseURL(u), form3.WithHTTPClient(c.Client()))
+	require.Empty(t, err)
+	return f3
+}
@@ -0,0 +1,222 @@
+package form3test
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+// Routes of the fake server, named after the operations of accounts.Service.
+const (
+	RouteAny           = ""
+	RouteCreateAccount = "accounts.Create"
+	RouteFetchAccount  = "accounts.Fetch"
+	RouteUpdateAccount = "accounts.Update"
+	RouteDeleteAccount = "accounts.Delete"
+	RouteListAccounts  = "accounts.List"
+)
+
+// Fault describes how the server misbehaves. Latency is added before the response.
+// When Status is set the request isn't handled and the error response is sent instead,
+// otherwise the request is handled and its response is changed as described.
+type Fault struct {
+	Latency time.Duration
+
+	// Status of the error response, e.g. 503, with RetryAfter header when set.
+	Status     int
+	RetryAfter time.Duration
+
+	// Closes the connection without a response.
+	DropConnection bool
+
+	// Sends only half of the response body, then closes the connection.
+	TruncateBody bool
+
+	// Replaces the response body with invalid JSON.
+	MalformedJSON bool
+
+	// Replaces Content-Type of the response and, when Body is set, the body.
+	ContentType string
+	Body        string
+}
+
+// Latency delays the response by d.
+func Latency(d time.Duration) Fault {
+	return Fault{Latency: d}
+}
+
+// ServerError responds with given status, e.g. 500 or 503.
+func ServerError(status int) Fault {
+	return Fault{Status: status}
+}
+
+// RateLimited responds with 429 status and Retry-After header.
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// DropConnection closes the connection without a response.
+func DropConnection() Fault {
+	return Fault{DropConnection: true}
+}
+
+// TruncatedBody sends only half of the response body.
+func TruncatedBody() Fault {
+	return Fault{TruncateBody: true}
+}
+
+// MalformedJSON replaces the response body with invalid JSON.
+func MalformedJSON() Fault {
+	return Fault{MalformedJSON: true}
+}
+
+// WrongContentType replaces the response with an HTML page, like ones sent by proxies.
+func WrongContentType() Fault {
+	return Fault{ContentType: "text/html", Body: "<html><body><h1>Bad Gateway</h1></body></html>"}
+}
+
+// Trigger decides whether a fault applies to the n-th call of its route, counted from 1.
+type Trigger func(n int) bool
+
+// Always applies the fault to every call.
+func Always() Trigger {
+	return func(int) bool { return true }
+}
+
+// OnCalls applies the fault to calls with given numbers, counted from 1.
+func OnCalls(numbers ...int) Trigger {
+	return func(n int) bool {
+		for _, c := range numbers {
+			if c == n {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FirstCalls applies the fault to the first n calls, e.g. to simulate a burst of errors.
+func FirstCalls(count int) Trigger {
+	return func(n int) bool { return n <= count }
+}
+
+// EveryNth applies the fault to every n-th call.
+func EveryNth(every int) Trigger {
+	return func(n int) bool { return every > 0 && n%every == 0 }
+}
+
+// WithProbability applies the fault randomly with given probability. The same seed
+// gives the same sequence of faults, so tests are deterministic.
+func WithProbability(p float64, seed int64) Trigger {
+	rnd := rand.New(rand.NewSource(seed))
+	return func(int) bool { return rnd.Float64() < p }
+}
+
+type faultRule struct {
+	route   string
+	fault   Fault
+	trigger Trigger
+}
+
+// Inject adds a fault to calls of given route, or all routes with RouteAny, selected by the trigger.
+// When many faults apply to a call, the first injected one is used.
+func (s *Server) Inject(route string, f Fault, when Trigger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, faultRule{route: route, fault: f, trigger: when})
+}
+
+// ClearFaults removes all injected faults. Call counts are kept.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Calls returns the number of calls of given route, or all routes with RouteAny,
+// including calls that failed because of faults.
+func (s *Server) Calls(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[route]
+}
+
+// fault counts the call and returns the fault that applies to it, if any.
+func (s *Server) fault(route string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[RouteAny]++
+	if route != RouteAny {
+		s.calls[route]++
+	}
+	for _, r := range s.faults {
+		if r.route != RouteAny && r.route != route {
+			continue
+		}
+		if r.trigger(s.calls[r.route]) {
+			return r.fault, true
+		}
+	}
+	return Fault{}, false
+}
+
+// serveFault serves the request with the fault, calling handle when the request should be handled.
+func serveFault(w http.ResponseWriter, r *http.Request, f Fault, handle http.HandlerFunc) {
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if f.DropConnection {
+		dropConnection()
+	}
+	if f.Status != 0 {
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+		}
+		writeError(w, f.Status, fmt.Sprintf("injected fault: %s", http.StatusText(f.Status)))
+		return
+	}
+	if !f.TruncateBody && !f.MalformedJSON && f.ContentType == "" {
+		handle(w, r)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	handle(rec, r)
+	body := rec.Body.Bytes()
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	switch {
+	case f.MalformedJSON:
+		body = []byte(`{"data": {"id": [}`)
+	case f.Body != "":
+		body = []byte(f.Body)
+	}
+	if f.ContentType != "" {
+		w.Header().Set("Content-Type", f.ContentType)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if f.TruncateBody {
+		body = body[:len(body)/2]
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(body)
+	if f.TruncateBody {
+		if fl, ok := w.(http.Flusher); ok {
+			fl.Flush()
+		}
+		dropConnection()
+	}
+}
+
+// dropConnection aborts the handler, so the server closes the connection.
+func dropConnection() {
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,143 @@
+package form3test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/althink/form3"
+	"github.com/althink/form3/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = form3.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func Test_Faults_ServerErrorBurst_Retried(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t, form3.WithRetryPolicy(testRetryPolicy))
+	acc := newAccount("GB")
+	fake.AddAccount(acc)
+	fake.Inject(RouteFetchAccount, ServerError(503), FirstCalls(2))
+
+	// when
+	fetched, err := f3.Accounts.Fetch(ctx, acc.ID)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, acc.ID, fetched.Data.ID)
+	require.Equal(t, 3, fake.Calls(RouteFetchAccount))
+}
+
+func Test_Faults_RateLimited(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t)
+	fake.Inject(RouteListAccounts, RateLimited(2*time.Second), Always())
+
+	// when
+	_, err := f3.Accounts.List(ctx, accounts.ListOptions{})
+
+	// then
+	require.IsType(t, &accounts.RateLimitedError{}, err, "Invalid error type")
+	require.Equal(t, 2*time.Second, err.(*accounts.RateLimitedError).RetryAfter)
+}
+
+func Test_Faults_DropConnection_Retried(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t, form3.WithRetryPolicy(testRetryPolicy))
+	fake.Inject(RouteCreateAccount, DropConnection(), OnCalls(1))
+	acc := newAccount("GB")
+
+	// when
+	created, err := f3.Accounts.Create(ctx, acc)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, acc.ID, created.Data.ID)
+	require.Equal(t, 2, fake.Calls(RouteCreateAccount))
+}
+
+func Test_Faults_BrokenResponses(t *testing.T) {
+	tests := map[string]Fault{
+		"truncated body":     TruncatedBody(),
+		"malformed JSON":     MalformedJSON(),
+		"wrong content type": WrongContentType(),
+	}
+	for name, fault := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			fake, f3 := setUp(t)
+			acc := newAccount("GB")
+			fake.AddAccount(acc)
+			fake.Inject(RouteFetchAccount, fault, Always())
+
+			// when
+			_, err := f3.Accounts.Fetch(ctx, acc.ID)
+
+			// then
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_Faults_Latency_ContextDeadline(t *testing.T) {
+	// given
+	fake, f3 := setUp(t)
+	fake.Inject(RouteAny, Latency(time.Second), Always())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := f3.Accounts.List(ctx, accounts.ListOptions{})
+
+	// then
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "Invalid error: %v", err)
+}
+
+func Test_Faults_WithProbability_Deterministic(t *testing.T) {
+	// given
+	run := func() []bool {
+		ctx := context.Background()
+		fake, f3 := setUp(t)
+		fake.Inject(RouteListAccounts, ServerError(500), WithProbability(0.5, 42))
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			_, err := f3.Accounts.List(ctx, accounts.ListOptions{})
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	// when
+	first, second := run(), run()
+
+	// then
+	require.Equal(t, first, second)
+	require.Contains(t, first, true)
+	require.Contains(t, first, false)
+}
+
+func Test_Faults_EveryNthAndClear(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t)
+	fake.Inject(RouteAny, ServerError(502), EveryNth(2))
+
+	// when
+	_, err1 := f3.Accounts.List(ctx, accounts.ListOptions{})
+	_, err2 := f3.Accounts.List(ctx, accounts.ListOptions{})
+	fake.ClearFaults()
+	_, err3 := f3.Accounts.List(ctx, accounts.ListOptions{})
+	_, err4 := f3.Accounts.List(ctx, accounts.ListOptions{})
+
+	// then
+	require.Empty(t, err1)
+	require.IsType(t, &accounts.HttpStatusError{}, err2, "Invalid error type")
+	require.Empty(t, err3)
+	require.Empty(t, err4)
+	require.Equal(t, 4, fake.Calls(RouteAny))
+}
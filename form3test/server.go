@@ -0,0 +1,308 @@
+// Package form3test provides an in-memory fake of Form3 API for tests.
+//
+// The fake server mirrors the behaviour of the organisation/accounts API of the Form3 account API
+// image used by integration tests, so clients can be tested offline. Faults, like latency, errors
+// or dropped connections, can be injected to test how clients handle misbehaving API, see Server.Inject.
+//
+//	fake := form3test.NewServer()
+//	defer fake.Close()
+//	f3, err := form3.NewClient(form3.WithBaseURL(fake.URL()))
+package form3test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/althink/form3/accounts"
+	"github.com/althink/form3/accounts/accountsmock"
+	"github.com/google/uuid"
+)
+
+const (
+	basePath     = "/v1/"
+	accountsPath = "/v1/organisation/accounts"
+	mediaType    = "application/vnd.api+json"
+
+	// Page size used when the request doesn't set one.
+	DefaultPageSize = accountsmock.DefaultPageSize
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Server is a fake Form3 API server. Accounts are kept in accountsmock.Store, so the server
+// and the in-memory service have the same semantics. It's safe for concurrent use.
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	accounts *accountsmock.Store
+
+	faults []faultRule
+	// number of calls by route
+	calls map[string]int
+}
+
+// NewServer starts a fake server with no accounts. It should be closed when no longer used.
+func NewServer() *Server {
+	s := &Server{accounts: accountsmock.NewStore(), calls: map[string]int{}}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the API, with the trailing slash, to be used with form3.WithBaseURL.
+func (s *Server) URL() url.URL {
+	u, _ := url.Parse(s.srv.URL + basePath)
+	return *u
+}
+
+// Client returns HTTP client configured for the server.
+func (s *Server) Client() *http.Client {
+	return s.srv.Client()
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AddAccount stores the account as if it was created. Missing type and version are set.
+func (s *Server) AddAccount(a *accounts.Data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts.Add(a)
+}
+
+// Account returns a copy of the stored account with given ID, or nil.
+func (s *Server) Account(id string) *accounts.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, _ := s.accounts.Account(id)
+	return a
+}
+
+// Accounts returns copies of all stored accounts in creation order.
+func (s *Server) Accounts() []*accounts.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accounts.Accounts()
+}
+
+// Reset removes all accounts.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts = accountsmock.NewStore()
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	route, handle := s.route(r)
+	if f, ok := s.fault(route); ok {
+		serveFault(w, r, f, handle)
+		return
+	}
+	handle(w, r)
+}
+
+// route returns the name of the route and its handler.
+func (s *Server) route(r *http.Request) (string, http.HandlerFunc) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == accountsPath && r.Method == "POST":
+		return RouteCreateAccount, s.locked(s.createAccount)
+	case path == accountsPath && r.Method == "GET":
+		return RouteListAccounts, s.locked(s.listAccounts)
+	case strings.HasPrefix(path, accountsPath+"/") && !strings.Contains(path[len(accountsPath)+1:], "/"):
+		id := path[len(accountsPath)+1:]
+		switch r.Method {
+		case "GET":
+			return RouteFetchAccount, s.locked(func(w http.ResponseWriter, r *http.Request) {
+				s.fetchAccount(w, r, id)
+			})
+		case "PATCH":
+			return RouteUpdateAccount, s.locked(func(w http.ResponseWriter, r *http.Request) {
+				s.updateAccount(w, r, id)
+			})
+		case "DELETE":
+			return RouteDeleteAccount, s.locked(func(w http.ResponseWriter, r *http.Request) {
+				s.deleteAccount(w, r, id)
+			})
+		}
+		return RouteAny, methodNotAllowed
+	case path == accountsPath:
+		return RouteAny, methodNotAllowed
+	}
+	return RouteAny, func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "resource not found")
+	}
+}
+
+func (s *Server) locked(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		h(w, r)
+	}
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Data *accounts.Data `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if msgs := validate(body.Data); len(msgs) > 0 {
+		writeError(w, http.StatusBadRequest, "validation failure list:\n"+strings.Join(msgs, "\n"))
+		return
+	}
+	created, err := s.accounts.Create(r.Context(), body.Data)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeResource(w, http.StatusCreated, created.Data)
+}
+
+func (s *Server) fetchAccount(w http.ResponseWriter, r *http.Request, id string) {
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+	fetched, err := s.accounts.Fetch(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeResource(w, http.StatusOK, fetched.Data)
+}
+
+func (s *Server) updateAccount(w http.ResponseWriter, r *http.Request, id string) {
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+	var body struct {
+		Data *accounts.Data `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Data == nil || body.Data.Version == nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: data with version is required")
+		return
+	}
+	updated, err := s.accounts.Update(r.Context(), id, *body.Data.Version, body.Data.Attributes)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeResource(w, http.StatusOK, updated.Data)
+}
+
+func (s *Server) deleteAccount(w http.ResponseWriter, r *http.Request, id string) {
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+	err = s.accounts.Delete(r.Context(), id, version)
+	if errors.Is(err, accounts.ErrNotFound) {
+		// the API responds with an empty body
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	opts, err := accountsmock.ListOptionsFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid page parameters")
+		return
+	}
+	page, err := s.accounts.List(r.Context(), opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": page.Data, "links": page.Links})
+}
+
+// writeStoreError writes the error response of the API failing with given error of the store.
+func writeStoreError(w http.ResponseWriter, err error) {
+	if e, ok := accounts.AsAPIError(err); ok && e.StatusCode != 0 {
+		writeError(w, e.StatusCode, e.Msg)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+// validate returns messages for invalid fields, in the format of the Form3 API.
+func validate(a *accounts.Data) []string {
+	if a == nil {
+		return []string{"data in body is required"}
+	}
+	var msgs []string
+	if _, err := uuid.Parse(a.ID); err != nil {
+		msgs = append(msgs, fmt.Sprintf("id in body must be of type uuid: %q", a.ID))
+	}
+	if _, err := uuid.Parse(a.OrganisationID); err != nil {
+		msgs = append(msgs, fmt.Sprintf("organisation_id in body must be of type uuid: %q", a.OrganisationID))
+	}
+	if a.Type != accounts.Type {
+		msgs = append(msgs, fmt.Sprintf("type in body should be one of [%s]", accounts.Type))
+	}
+	if a.Attributes == nil {
+		return append(msgs, "attributes in body is required")
+	}
+	if a.Attributes.Country == "" {
+		msgs = append(msgs, "country in body is required")
+	} else if !countryPattern.MatchString(a.Attributes.Country) {
+		msgs = append(msgs, "country in body should match '^[A-Z]{2}$'")
+	}
+	if len(a.Attributes.Name) == 0 {
+		msgs = append(msgs, "name in body is required")
+	} else if len(a.Attributes.Name) > 4 {
+		msgs = append(msgs, "name in body should have at most 4 items")
+	}
+	if s := a.Attributes.Status; s != nil && !s.IsKnown() {
+		msgs = append(msgs, "status in body should be one of [pending confirmed closed failed]")
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+func writeResource(w http.ResponseWriter, status int, a *accounts.Data) {
+	self := accountsPath + "/" + a.ID
+	writeJSON(w, status, map[string]interface{}{
+		"data":  a,
+		"links": accounts.Links{Self: &self},
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error_message": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,138 @@
+package form3test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/althink/form3"
+	"github.com/althink/form3/accounts"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Server_CreateFetchUpdateDeleteSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t)
+	acc := newAccount("GB")
+
+	// when
+	created, createErr := f3.Accounts.Create(ctx, acc)
+	fetched, fetchErr := f3.Accounts.Fetch(ctx, acc.ID)
+	updated, updateErr := f3.Accounts.Update(ctx, acc.ID, 0, &accounts.Attributes{BankID: "400300"})
+	deleteErr := f3.Accounts.Delete(ctx, acc.ID, 1)
+
+	// then
+	require.Empty(t, createErr)
+	require.Empty(t, fetchErr)
+	require.Empty(t, updateErr)
+	require.Empty(t, deleteErr)
+	require.EqualValues(t, 0, *created.Data.Version)
+	require.Equal(t, acc.Attributes.Name, fetched.Data.Attributes.Name)
+	require.EqualValues(t, 1, *updated.Data.Version)
+	require.Equal(t, "400300", updated.Data.Attributes.BankID)
+	require.Equal(t, "GB", updated.Data.Attributes.Country)
+	require.Empty(t, fake.Accounts())
+}
+
+func Test_Server_CreateFailed(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t)
+	existing := newAccount("GB")
+	fake.AddAccount(existing)
+
+	// when
+	_, duplicateErr := f3.Accounts.Create(ctx, existing)
+	_, invalidErr := f3.Accounts.Create(ctx, &accounts.Data{Attributes: &accounts.Attributes{}})
+
+	// then
+	require.IsType(t, &accounts.AccountAlreadyExistsError{}, duplicateErr, "Invalid error type")
+	require.IsType(t, &accounts.InvalidDataError{}, invalidErr, "Invalid error type")
+	require.Contains(t, invalidErr.(*accounts.InvalidDataError).Msg, "country in body is required")
+}
+
+func Test_Server_FetchAndDeleteFailed(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t)
+	existing := newAccount("GB")
+	fake.AddAccount(existing)
+	unknown := uuid.New().String()
+
+	// when
+	_, notFoundErr := f3.Accounts.Fetch(ctx, unknown)
+	_, invalidErr := f3.Accounts.Fetch(ctx, "invalid")
+	deleteNotFoundErr := f3.Accounts.Delete(ctx, unknown, 0)
+	deleteVersionErr := f3.Accounts.Delete(ctx, existing.ID, 7)
+	_, updateVersionErr := f3.Accounts.Update(ctx, existing.ID, 7, &accounts.Attributes{})
+
+	// then
+	require.IsType(t, &accounts.AccountNotFoundError{}, notFoundErr, "Invalid error type")
+	require.IsType(t, &accounts.InvalidDataError{}, invalidErr, "Invalid error type")
+	require.IsType(t, &accounts.AccountNotFoundError{}, deleteNotFoundErr, "Invalid error type")
+	require.IsType(t, &accounts.InvalidVersionError{}, deleteVersionErr, "Invalid error type")
+	require.IsType(t, &accounts.InvalidVersionError{}, updateVersionErr, "Invalid error type")
+	require.NotNil(t, fake.Account(existing.ID))
+}
+
+func Test_Server_ListSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake, f3 := setUp(t)
+	var gb []string
+	for _, country := range []string{"GB", "FR", "GB", "GB", "DE", "GB", "GB"} {
+		acc := newAccount(country)
+		fake.AddAccount(acc)
+		if country == "GB" {
+			gb = append(gb, acc.ID)
+		}
+	}
+
+	// when
+	var ids []string
+	pages := 0
+	page, err := f3.Accounts.List(ctx, accounts.ListOptions{PageSize: 2, Filter: accounts.ListFilter{Country: "GB"}})
+	for err == nil && page != nil {
+		pages++
+		for _, a := range page.Data {
+			ids = append(ids, a.ID)
+		}
+		page, err = f3.Accounts.ListNext(ctx, page)
+	}
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 3, pages)
+	require.Equal(t, gb, ids)
+}
+
+func Test_Server_ListSuccess_Empty(t *testing.T) {
+	// given
+	ctx := context.Background()
+	_, f3 := setUp(t)
+
+	// when
+	page, err := f3.Accounts.List(ctx, accounts.ListOptions{PageNumber: 3})
+
+	// then
+	require.Empty(t, err)
+	require.Empty(t, page.Data)
+	require.False(t, page.HasNext())
+	require.Equal(t, "/v1/organisation/accounts?page%5Bnumber%5D=0&page%5Bsize%5D=100", *page.Links.First)
+}
+
+func setUp(t *testing.T, opts ...form3.Option) (*Server, *form3.Form3) {
+	fake := NewServer()
+	t.Cleanup(fake.Close)
+	f3, err := form3.NewClient(append([]form3.Option{form3.WithBaseURL(fake.URL())}, opts...)...)
+	require.Empty(t, err)
+	return fake, f3
+}
+
+func newAccount(country string) *accounts.Data {
+	return accounts.NewWithGenID(uuid.New().String(), &accounts.Attributes{
+		Country: country,
+		Name:    []string{"Samantha Holder"},
+	})
+}
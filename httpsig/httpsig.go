@@ -0,0 +1,128 @@
+// Package httpsig signs and verifies HTTP requests as required by Form3 API,
+// following draft-cavage-http-signatures.
+//
+// Signed requests carry a SHA-256 Digest header of the body and a Signature header
+// computed over (request-target), host, date, digest and, when present, content-type.
+// RSA keys produce rsa-sha256 signatures and ECDSA keys produce ecdsa-sha256 signatures.
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmRSASHA256   = "rsa-sha256"
+	AlgorithmECDSASHA256 = "ecdsa-sha256"
+
+	// Pseudo header with the lower case method and request URI
+	RequestTarget = "(request-target)"
+)
+
+// Signer adds Digest and Signature headers to requests. It's safe for concurrent use.
+type Signer struct {
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+}
+
+// NewSigner creates a signer using given private key. Key ID is sent in the signature,
+// so the server knows which public key verifies it.
+func NewSigner(keyID string, key crypto.Signer) (*Signer, error) {
+	alg, err := algorithmOf(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{keyID: keyID, key: key, algorithm: alg}, nil
+}
+
+// Sign sets Date (if missing), Digest and Signature headers of the request.
+// The request body is read and replaced, so it can still be sent.
+func (s *Signer) Sign(req *http.Request) error {
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Digest", digest(body))
+
+	headers := []string{RequestTarget, "host", "date", "digest"}
+	if req.Header.Get("Content-Type") != "" {
+		headers = append(headers, "content-type")
+	}
+	hashed := sha256.Sum256([]byte(signingString(req, headers)))
+	sig, err := s.key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("could not sign request: %w", err)
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.keyID, s.algorithm, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+func algorithmOf(pub crypto.PublicKey) (string, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRSASHA256, nil
+	case *ecdsa.PublicKey:
+		return AlgorithmECDSASHA256, nil
+	}
+	return "", fmt.Errorf("unsupported key type: %T", pub)
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// signingString builds the string to sign from given headers of the request.
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		var v string
+		switch h {
+		case RequestTarget:
+			v = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "host":
+			v = req.Host
+			if v == "" {
+				v = req.URL.Host
+			}
+		default:
+			v = strings.Join(req.Header.Values(h), ", ")
+		}
+		lines[i] = h + ": " + v
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readBody reads the whole request body and replaces it with a fresh reader.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
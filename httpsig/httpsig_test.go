@@ -0,0 +1,169 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Signer_SignSuccess_RSA(t *testing.T) {
+	// given
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	s, _ := NewSigner("key-1", key)
+	req := newRequest("POST", `{"data": {}}`)
+
+	// when
+	err := s.Sign(req)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "SHA-256=WYz50v+iSYN1MxEKu6HIgx2j0c5CvQip2PIAGHX4IxA=", req.Header.Get("Digest"))
+	require.Regexp(t, regexp.MustCompile(`^keyId="key-1",algorithm="rsa-sha256",headers="\(request-target\) host date digest content-type",signature="[A-Za-z0-9+/=]+"$`),
+		req.Header.Get("Signature"))
+	require.Empty(t, verifier(key.Public()).Verify(req))
+	body, _ := ioutil.ReadAll(req.Body)
+	require.Equal(t, `{"data": {}}`, string(body))
+}
+
+func Test_Signer_SignSuccess_ECDSA(t *testing.T) {
+	// given
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, _ := NewSigner("key-1", key)
+	req := newRequest("GET", "")
+
+	// when
+	err := s.Sign(req)
+
+	// then
+	require.Empty(t, err)
+	require.NotEmpty(t, req.Header.Get("Date"))
+	require.Contains(t, req.Header.Get("Signature"), `algorithm="ecdsa-sha256",headers="(request-target) host date digest"`)
+	require.Empty(t, verifier(key.Public()).Verify(req))
+}
+
+func Test_Signer_NewSignerFailed_UnsupportedKey(t *testing.T) {
+	// given
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+
+	// when
+	_, err := NewSigner("key-1", key)
+
+	// then
+	require.Error(t, err)
+}
+
+func Test_Verifier_VerifyFailed(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	s, _ := NewSigner("key-1", key)
+
+	tests := map[string]struct {
+		tamper func(req *http.Request)
+		key    crypto.PublicKey
+	}{
+		"body changed": {
+			tamper: func(req *http.Request) { req.Body = ioutil.NopCloser(bytes.NewBufferString(`{}`)) },
+			key:    key.Public(),
+		},
+		"header changed": {
+			tamper: func(req *http.Request) { req.Header.Set("Content-Type", "text/plain") },
+			key:    key.Public(),
+		},
+		"path changed": {
+			tamper: func(req *http.Request) { req.URL.Path = "/v1/organisation/accounts/2" },
+			key:    key.Public(),
+		},
+		"missing signature": {
+			tamper: func(req *http.Request) { req.Header.Del("Signature") },
+			key:    key.Public(),
+		},
+		"other key": {
+			tamper: func(req *http.Request) {},
+			key:    other.Public(),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			req := newRequest("POST", `{"data": {}}`)
+			require.Empty(t, s.Sign(req))
+			tc.tamper(req)
+
+			// when
+			err := verifier(tc.key).Verify(req)
+
+			// then
+			require.True(t, errors.Is(err, ErrInvalidSignature), "Invalid error: %v", err)
+		})
+	}
+}
+
+func Test_Verifier_VerifyFailed_DateSkew(t *testing.T) {
+	// given
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	s, _ := NewSigner("key-1", key)
+	req := newRequest("GET", "")
+	req.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	require.Empty(t, s.Sign(req))
+	v := verifier(key.Public())
+	v.MaxSkew = 5 * time.Minute
+
+	// when
+	err := v.Verify(req)
+
+	// then
+	require.True(t, errors.Is(err, ErrInvalidSignature), "Invalid error: %v", err)
+}
+
+func Test_Verifier_Middleware(t *testing.T) {
+	// given
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, _ := NewSigner("key-1", key)
+	srv := httptest.NewServer(verifier(key.Public()).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	})))
+	defer srv.Close()
+
+	signed, _ := http.NewRequest("POST", srv.URL+"/v1/organisation/accounts?filter%5Bcountry%5D=GB", bytes.NewBufferString(`{"data": {}}`))
+	signed.Header.Set("Content-Type", "application/vnd.api+json")
+	require.Empty(t, s.Sign(signed))
+	unsigned, _ := http.NewRequest("GET", srv.URL+"/v1/organisation/accounts", nil)
+
+	// when
+	signedResp, err1 := http.DefaultClient.Do(signed)
+	unsignedResp, err2 := http.DefaultClient.Do(unsigned)
+
+	// then
+	require.Empty(t, err1)
+	require.Empty(t, err2)
+	require.Equal(t, 200, signedResp.StatusCode)
+	body, _ := ioutil.ReadAll(signedResp.Body)
+	require.Equal(t, `{"data": {}}`, string(body))
+	require.Equal(t, 401, unsignedResp.StatusCode)
+}
+
+func newRequest(method, body string) *http.Request {
+	req, _ := http.NewRequest(method, "http://form3/v1/organisation/accounts/1", bytes.NewBufferString(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+	}
+	return req
+}
+
+func verifier(key crypto.PublicKey) *Verifier {
+	return NewVerifier(StaticKeys(map[string]crypto.PublicKey{"key-1": key}))
+}
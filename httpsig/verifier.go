@@ -0,0 +1,155 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// KeyLookup returns the public key for given key ID.
+type KeyLookup func(keyID string) (crypto.PublicKey, error)
+
+// Verifier checks Digest and Signature headers of incoming requests.
+// It's meant for local stand-in servers and tests.
+type Verifier struct {
+	keys KeyLookup
+
+	// Maximum difference between Date header and current time. Zero disables the check.
+	MaxSkew time.Duration
+
+	// Headers that must be covered by the signature.
+	// Defaults to (request-target), host, date and digest.
+	Required []string
+}
+
+func NewVerifier(keys KeyLookup) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		Required: []string{RequestTarget, "host", "date", "digest"},
+	}
+}
+
+// StaticKeys returns KeyLookup that finds keys in given map.
+func StaticKeys(keys map[string]crypto.PublicKey) KeyLookup {
+	return func(keyID string) (crypto.PublicKey, error) {
+		k, ok := keys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("unknown key: %q", keyID)
+		}
+		return k, nil
+	}
+}
+
+// Verify checks the signature and digest of the request. Returns error wrapping
+// ErrInvalidSignature when the request is not properly signed.
+// The request body is read and replaced, so it can still be handled.
+func (v *Verifier) Verify(req *http.Request) error {
+	params, err := parseSignature(req.Header.Get("Signature"))
+	if err != nil {
+		return err
+	}
+	headers := strings.Fields(params["headers"])
+	if len(headers) == 0 {
+		headers = []string{"date"}
+	}
+	for _, r := range v.Required {
+		if !contains(headers, r) {
+			return fmt.Errorf("%w: header %s is not signed", ErrInvalidSignature, r)
+		}
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	if contains(headers, "digest") && req.Header.Get("Digest") != digest(body) {
+		return fmt.Errorf("%w: digest does not match body", ErrInvalidSignature)
+	}
+
+	if v.MaxSkew > 0 {
+		date, err := http.ParseTime(req.Header.Get("Date"))
+		if err != nil {
+			return fmt.Errorf("%w: invalid date: %v", ErrInvalidSignature, err)
+		}
+		if skew := time.Since(date); skew > v.MaxSkew || skew < -v.MaxSkew {
+			return fmt.Errorf("%w: date is %s off", ErrInvalidSignature, skew)
+		}
+	}
+
+	key, err := v.keys(params["keyId"])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	alg, err := algorithmOf(key)
+	if err != nil {
+		return err
+	}
+	if a := params["algorithm"]; a != "" && a != alg {
+		return fmt.Errorf("%w: algorithm %s does not match key", ErrInvalidSignature, a)
+	}
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64 encoded", ErrInvalidSignature)
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, headers)))
+	var ok bool
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], sig) == nil
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, hashed[:], sig)
+	}
+	if !ok {
+		return fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// Middleware rejects requests that fail verification with 401 status.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseSignature parses Signature header in `name="value",name="value"` format.
+func parseSignature(h string) (map[string]string, error) {
+	if h == "" {
+		return nil, fmt.Errorf("%w: missing Signature header", ErrInvalidSignature)
+	}
+	params := map[string]string{}
+	for _, p := range strings.Split(h, ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) != 2 || len(kv[1]) < 2 || kv[1][0] != '"' || kv[1][len(kv[1])-1] != '"' {
+			return nil, fmt.Errorf("%w: malformed Signature header", ErrInvalidSignature)
+		}
+		params[kv[0]] = kv[1][1 : len(kv[1])-1]
+	}
+	if params["keyId"] == "" || params["signature"] == "" {
+		return nil, fmt.Errorf("%w: Signature header must have keyId and signature", ErrInvalidSignature)
+	}
+	return params, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package iban
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/althink/form3/accounts"
+)
+
+// FromAttributes builds an IBAN from Country, BankID, AccountNumber and,
+// where the BBAN requires it, the institution code of the Bic.
+func FromAttributes(a *accounts.Attributes) (string, error) {
+	return Build(partsOf(a))
+}
+
+// Fill sets the Iban of attributes computed from other fields, when it's not set yet.
+func Fill(a *accounts.Attributes) error {
+	if a.Iban != "" {
+		return nil
+	}
+	iban, err := FromAttributes(a)
+	if err != nil {
+		return err
+	}
+	a.Iban = iban
+	return nil
+}
+
+// Check validates the Iban of attributes and verifies that it agrees with
+// Country, BankID, AccountNumber and Bic. Attributes without Iban are valid.
+// Consistency with other fields is checked only in countries supported by Build.
+func Check(a *accounts.Attributes) error {
+	if a.Iban == "" {
+		return nil
+	}
+	if err := Validate(a.Iban); err != nil {
+		return err
+	}
+	iban := Normalize(a.Iban)
+	if a.Country != "" && iban[:2] != a.Country {
+		return &MismatchError{Field: "country", IBAN: iban, Value: a.Country}
+	}
+
+	p, err := Split(iban)
+	if err != nil {
+		// structure of unsupported countries is valid, but nothing can be compared
+		return nil
+	}
+	want := partsOf(a)
+	if want.BankCode != "" && p.BankCode != "" && want.BankCode != p.BankCode {
+		return &MismatchError{Field: "bic", IBAN: iban, Value: a.Bic}
+	}
+	if want.BankID != "" && !strings.HasSuffix(strings.ToUpper(want.BankID), p.BankID) {
+		return &MismatchError{Field: "bank_id", IBAN: iban, Value: a.BankID}
+	}
+	if want.AccountNumber != "" && trimZeros(want.AccountNumber) != trimZeros(p.AccountNumber) {
+		return &MismatchError{Field: "account_number", IBAN: iban, Value: a.AccountNumber}
+	}
+	return nil
+}
+
+// MismatchError is returned by Check when the IBAN doesn't agree with other attributes.
+type MismatchError struct {
+	Field string
+	IBAN  string
+	Value string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("iban %s does not match %s %q", e.IBAN, e.Field, e.Value)
+}
+
+func partsOf(a *accounts.Attributes) Parts {
+	p := Parts{
+		Country:       a.Country,
+		BankID:        a.BankID,
+		AccountNumber: a.AccountNumber,
+	}
+	if b, err := accounts.ParseBIC(a.Bic); err == nil {
+		p.BankCode = b.Institution
+	}
+	return p
+}
+
+func trimZeros(s string) string {
+	return strings.TrimLeft(strings.ToUpper(s), "0")
+}
@@ -0,0 +1,276 @@
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedCountry = errors.New("country not supported for IBAN composition")
+
+// Parts are the components of an IBAN as they are stored in Form3 account attributes.
+type Parts struct {
+	// ISO 3166-1 country code, e.g. "GB"
+	Country string
+
+	// Institution code taken from the first four characters of the BIC.
+	// It's a part of the BBAN in GB, IE and NL only.
+	BankCode string
+
+	// Local country bank identifier, e.g. sort code in GB or BLZ in DE
+	BankID string
+
+	// Account number. It's left padded with zeros to the length required by the BBAN.
+	AccountNumber string
+}
+
+type field int
+
+const (
+	bankCode field = iota
+	bankID
+	account
+	// national check digits computed from bank ID and account number
+	check
+)
+
+type part struct {
+	field  field
+	length int
+}
+
+type layout struct {
+	parts []part
+	// check computes national check digits from bank ID and padded account number.
+	check func(bankID, account string) (string, error)
+}
+
+var layouts = map[string]layout{
+	"AT": {parts: []part{{bankID, 5}, {account, 11}}},
+	"BE": {parts: []part{{bankID, 3}, {account, 7}, {check, 2}}, check: checkBE},
+	"CH": {parts: []part{{bankID, 5}, {account, 12}}},
+	"DE": {parts: []part{{bankID, 8}, {account, 10}}},
+	"ES": {parts: []part{{bankID, 8}, {check, 2}, {account, 10}}, check: checkES},
+	"FR": {parts: []part{{bankID, 10}, {account, 11}, {check, 2}}, check: checkFR},
+	"GB": {parts: []part{{bankCode, 4}, {bankID, 6}, {account, 8}}},
+	"GR": {parts: []part{{bankID, 7}, {account, 16}}},
+	"IE": {parts: []part{{bankCode, 4}, {bankID, 6}, {account, 8}}},
+	"IT": {parts: []part{{check, 1}, {bankID, 10}, {account, 12}}, check: checkIT},
+	"LU": {parts: []part{{bankID, 3}, {account, 13}}},
+	"NL": {parts: []part{{bankCode, 4}, {account, 10}}},
+	"PL": {parts: []part{{bankID, 8}, {account, 16}}},
+	"PT": {parts: []part{{bankID, 8}, {account, 11}, {check, 2}}, check: checkPT},
+}
+
+// Build composes an IBAN from its parts, computing national and IBAN check digits.
+// Account numbers shorter than the BBAN field are left padded with zeros.
+//
+// In IT the bank ID may be given with the leading CIN check character,
+// otherwise the CIN is computed.
+func Build(p Parts) (string, error) {
+	country := strings.ToUpper(p.Country)
+	l, ok := layouts[country]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedCountry, country)
+	}
+
+	bankIDVal := strings.ToUpper(p.BankID)
+	var nationalCheck string
+	if country == "IT" && len(bankIDVal) == 11 {
+		nationalCheck, bankIDVal = bankIDVal[:1], bankIDVal[1:]
+	}
+
+	values := map[field]string{bankCode: strings.ToUpper(p.BankCode), bankID: bankIDVal}
+	for _, pt := range l.parts {
+		v := values[pt.field]
+		switch pt.field {
+		case account:
+			acc := strings.ToUpper(p.AccountNumber)
+			if len(acc) > pt.length {
+				return "", fmt.Errorf("%w: %s account number must have up to %d characters", ErrInvalidFormat, country, pt.length)
+			}
+			values[account] = strings.Repeat("0", pt.length-len(acc)) + acc
+		case bankCode, bankID:
+			if len(v) != pt.length {
+				return "", fmt.Errorf("%w: %s %s must have %d characters", ErrInvalidFormat, country, fieldName(pt.field), pt.length)
+			}
+		}
+	}
+
+	if l.check != nil && nationalCheck == "" {
+		c, err := l.check(values[bankID], values[account])
+		if err != nil {
+			return "", err
+		}
+		nationalCheck = c
+	}
+	values[check] = nationalCheck
+
+	var bban strings.Builder
+	for _, pt := range l.parts {
+		bban.WriteString(values[pt.field])
+	}
+	return FromBBAN(country, bban.String())
+}
+
+// Split validates the IBAN and extracts bank code, bank ID and account number from it.
+// Account numbers are returned with the padding, as they are stored in the BBAN.
+func Split(iban string) (Parts, error) {
+	iban = Normalize(iban)
+	if err := Validate(iban); err != nil {
+		return Parts{}, err
+	}
+	country := iban[:2]
+	l, ok := layouts[country]
+	if !ok {
+		return Parts{}, fmt.Errorf("%w: %q", ErrUnsupportedCountry, country)
+	}
+
+	p := Parts{Country: country}
+	pos := 4
+	for _, pt := range l.parts {
+		v := iban[pos : pos+pt.length]
+		switch pt.field {
+		case bankCode:
+			p.BankCode = v
+		case bankID:
+			p.BankID = v
+		case account:
+			p.AccountNumber = v
+		}
+		pos += pt.length
+	}
+	return p, nil
+}
+
+func fieldName(f field) string {
+	switch f {
+	case bankCode:
+		return "bank code"
+	case bankID:
+		return "bank ID"
+	case account:
+		return "account number"
+	default:
+		return "check digits"
+	}
+}
+
+func checkBE(bankID, account string) (string, error) {
+	n, err := strconv.ParseInt(bankID+account, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: BE bank ID and account number must be numeric", ErrInvalidFormat)
+	}
+	c := n % 97
+	if c == 0 {
+		c = 97
+	}
+	return fmt.Sprintf("%02d", c), nil
+}
+
+var esWeights = []int{1, 2, 4, 8, 5, 10, 9, 7, 3, 6}
+
+func checkES(bankID, account string) (string, error) {
+	digit := func(s string) (int, error) {
+		sum := 0
+		for i := 0; i < len(s); i++ {
+			if !isDigit(s[i]) {
+				return 0, fmt.Errorf("%w: ES bank ID and account number must be numeric", ErrInvalidFormat)
+			}
+			sum += int(s[i]-'0') * esWeights[i]
+		}
+		d := 11 - sum%11
+		switch d {
+		case 11:
+			return 0, nil
+		case 10:
+			return 1, nil
+		}
+		return d, nil
+	}
+	d1, err := digit("00" + bankID)
+	if err != nil {
+		return "", err
+	}
+	d2, err := digit(account)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d%d", d1, d2), nil
+}
+
+func checkPT(bankID, account string) (string, error) {
+	s := bankID + account
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return "", fmt.Errorf("%w: PT bank ID and account number must be numeric", ErrInvalidFormat)
+		}
+	}
+	return fmt.Sprintf("%02d", 98-mod97(s+"00")), nil
+}
+
+// checkFR computes the RIB key. Letters in the account number are replaced with digits
+// according to the RIB conversion table.
+func checkFR(bankID, account string) (string, error) {
+	conv := func(s string) (int64, error) {
+		var n int64
+		for i := 0; i < len(s); i++ {
+			ch := s[i]
+			var d int64
+			switch {
+			case isDigit(ch):
+				d = int64(ch - '0')
+			case ch >= 'A' && ch <= 'Z':
+				d = int64((ch-'A')%9 + 1)
+				if ch >= 'S' {
+					d = int64((ch-'A'+1)%9 + 1)
+				}
+			default:
+				return 0, fmt.Errorf("%w: FR bank ID and account number must be alphanumeric", ErrInvalidFormat)
+			}
+			n = n*10 + d
+		}
+		return n, nil
+	}
+	bank, err := conv(bankID[:5])
+	if err != nil {
+		return "", err
+	}
+	branch, err := conv(bankID[5:])
+	if err != nil {
+		return "", err
+	}
+	acc, err := conv(account)
+	if err != nil {
+		return "", err
+	}
+	key := 97 - (89*bank+15*branch+3*acc)%97
+	return fmt.Sprintf("%02d", key), nil
+}
+
+var itOdd = []int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23}
+
+// checkIT computes the CIN check character.
+func checkIT(bankID, account string) (string, error) {
+	s := bankID + account
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		var idx int
+		switch {
+		case isDigit(ch):
+			idx = int(ch - '0')
+		case ch >= 'A' && ch <= 'Z':
+			idx = int(ch - 'A')
+		default:
+			return "", fmt.Errorf("%w: IT bank ID and account number must be alphanumeric", ErrInvalidFormat)
+		}
+		if i%2 == 0 {
+			sum += itOdd[idx]
+		} else {
+			sum += idx
+		}
+	}
+	return string(rune('A' + sum%26)), nil
+}
@@ -0,0 +1,84 @@
+// Package iban validates, builds and decomposes International Bank Account Numbers.
+//
+// Validation covers the mod-97 checksum (ISO 13616) and the BBAN structure of every
+// country from the SWIFT IBAN registry. Building and decomposing IBANs from bank ID and
+// account number is supported for countries in which Form3 accounts can be created.
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownCountry  = errors.New("unknown IBAN country")
+	ErrInvalidLength   = errors.New("invalid IBAN length")
+	ErrInvalidFormat   = errors.New("invalid IBAN format")
+	ErrInvalidChecksum = errors.New("invalid IBAN checksum")
+)
+
+// Normalize removes spaces from the IBAN and converts it to upper case,
+// e.g. "gb82 west 1234 5698 7654 32" becomes "GB82WEST12345698765432".
+func Normalize(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+}
+
+// Validate checks the checksum and country specific structure of the IBAN.
+// The IBAN is normalized before validation.
+func Validate(iban string) error {
+	iban = Normalize(iban)
+	if len(iban) < 4 {
+		return fmt.Errorf("%w: %q", ErrInvalidLength, iban)
+	}
+	country := iban[:2]
+	s, ok := structures[country]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownCountry, country)
+	}
+	if len(iban) != s.length {
+		return fmt.Errorf("%w: %s IBAN must have %d characters, got %d", ErrInvalidLength, country, s.length, len(iban))
+	}
+	if !isDigit(iban[2]) || !isDigit(iban[3]) || !s.match(iban[4:]) {
+		return fmt.Errorf("%w: %q", ErrInvalidFormat, iban)
+	}
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return fmt.Errorf("%w: %q", ErrInvalidChecksum, iban)
+	}
+	return nil
+}
+
+// FromBBAN builds an IBAN from a country code and the BBAN by computing its check digits.
+func FromBBAN(country, bban string) (string, error) {
+	country = strings.ToUpper(country)
+	bban = Normalize(bban)
+	s, ok := structures[country]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCountry, country)
+	}
+	if !s.match(bban) {
+		return "", fmt.Errorf("%w: BBAN %q does not match %s structure %s", ErrInvalidFormat, bban, country, registry[country])
+	}
+	check := 98 - mod97(bban+country+"00")
+	return fmt.Sprintf("%s%02d%s", country, check, bban), nil
+}
+
+// mod97 computes the remainder of division by 97 of the number built by replacing
+// every letter of s with two digits (A = 10, B = 11, ..., Z = 35).
+func mod97(s string) int {
+	r := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case isDigit(ch):
+			r = (r*10 + int(ch-'0')) % 97
+		case ch >= 'A' && ch <= 'Z':
+			r = (r*100 + int(ch-'A') + 10) % 97
+		}
+	}
+	return r
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,172 @@
+package iban
+
+import (
+	"testing"
+
+	"github.com/althink/form3/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+var validIBANs = map[string]Parts{
+	"GB16NWBK40030041426819":       {Country: "GB", BankCode: "NWBK", BankID: "400300", AccountNumber: "41426819"},
+	"GB82WEST12345698765432":       {Country: "GB", BankCode: "WEST", BankID: "123456", AccountNumber: "98765432"},
+	"IE29AIBK93115212345678":       {Country: "IE", BankCode: "AIBK", BankID: "931152", AccountNumber: "12345678"},
+	"NL91ABNA0417164300":           {Country: "NL", BankCode: "ABNA", AccountNumber: "0417164300"},
+	"DE89370400440532013000":       {Country: "DE", BankID: "37040044", AccountNumber: "0532013000"},
+	"AT611904300234573201":         {Country: "AT", BankID: "19043", AccountNumber: "00234573201"},
+	"BE68539007547034":             {Country: "BE", BankID: "539", AccountNumber: "0075470"},
+	"CH9300762011623852957":        {Country: "CH", BankID: "00762", AccountNumber: "011623852957"},
+	"ES9121000418450200051332":     {Country: "ES", BankID: "21000418", AccountNumber: "0200051332"},
+	"FR1420041010050500013M02606":  {Country: "FR", BankID: "2004101005", AccountNumber: "0500013M026"},
+	"GR1601101250000000012300695":  {Country: "GR", BankID: "0110125", AccountNumber: "0000000012300695"},
+	"IT60X0542811101000000123456":  {Country: "IT", BankID: "0542811101", AccountNumber: "000000123456"},
+	"LU280019400644750000":         {Country: "LU", BankID: "001", AccountNumber: "9400644750000"},
+	"PL61109010140000071219812874": {Country: "PL", BankID: "10901014", AccountNumber: "0000071219812874"},
+	"PT50000201231234567890154":    {Country: "PT", BankID: "00020123", AccountNumber: "12345678901"},
+}
+
+func Test_IBAN_ValidateSuccess(t *testing.T) {
+	ibans := []string{
+		"gb82 west 1234 5698 7654 32",
+		"NO9386011117947",
+		"MT84MALT011000012345MTLCAST001S",
+		"BR1800360305000010009795493C1",
+		"SE4550000000058398257466",
+	}
+	for iban := range validIBANs {
+		ibans = append(ibans, iban)
+	}
+	for _, iban := range ibans {
+		t.Run(iban, func(t *testing.T) {
+			// when
+			err := Validate(iban)
+
+			// then
+			require.Empty(t, err)
+		})
+	}
+}
+
+func Test_IBAN_ValidateFailed(t *testing.T) {
+	tests := map[string]error{
+		"GB":                          ErrInvalidLength,
+		"XX82WEST12345698765432":      ErrUnknownCountry,
+		"GB82WEST1234569876543":       ErrInvalidLength,
+		"GB82WEST12345698765433":      ErrInvalidChecksum,
+		"GB82WES112345698765432":      ErrInvalidFormat,
+		"GBX2WEST12345698765432":      ErrInvalidFormat,
+		"DE89370400440532013001":      ErrInvalidChecksum,
+		"DE8937040044053201300A":      ErrInvalidFormat,
+		"NL91ABNA0417164301":          ErrInvalidChecksum,
+		"FR1420041010050500013M02607": ErrInvalidChecksum,
+	}
+	for iban, expected := range tests {
+		t.Run(iban, func(t *testing.T) {
+			// when
+			err := Validate(iban)
+
+			// then
+			require.ErrorIs(t, err, expected)
+		})
+	}
+}
+
+func Test_IBAN_BuildSuccess(t *testing.T) {
+	for iban, parts := range validIBANs {
+		t.Run(iban, func(t *testing.T) {
+			// when
+			built, err := Build(parts)
+
+			// then
+			require.Empty(t, err)
+			require.Equal(t, iban, built)
+		})
+	}
+}
+
+func Test_IBAN_BuildSuccess_PaddedAccountNumber(t *testing.T) {
+	// when
+	built, err := Build(Parts{Country: "DE", BankID: "37040044", AccountNumber: "532013000"})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "DE89370400440532013000", built)
+}
+
+func Test_IBAN_BuildSuccess_ITWithCIN(t *testing.T) {
+	// when
+	built, err := Build(Parts{Country: "IT", BankID: "X0542811101", AccountNumber: "000000123456"})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "IT60X0542811101000000123456", built)
+}
+
+func Test_IBAN_BuildFailed(t *testing.T) {
+	tests := map[string]struct {
+		parts    Parts
+		expected error
+	}{
+		"unsupported country": {Parts{Country: "US", BankID: "021000021", AccountNumber: "123456"}, ErrUnsupportedCountry},
+		"missing bank code":   {Parts{Country: "GB", BankID: "400300", AccountNumber: "41426819"}, ErrInvalidFormat},
+		"too long account":    {Parts{Country: "DE", BankID: "37040044", AccountNumber: "12345678901"}, ErrInvalidFormat},
+		"non numeric account": {Parts{Country: "PL", BankID: "10901014", AccountNumber: "ABC"}, ErrInvalidFormat},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			_, err := Build(tt.parts)
+
+			// then
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func Test_IBAN_SplitSuccess(t *testing.T) {
+	for iban, parts := range validIBANs {
+		t.Run(iban, func(t *testing.T) {
+			// when
+			split, err := Split(iban)
+
+			// then
+			require.Empty(t, err)
+			require.Equal(t, parts, split)
+		})
+	}
+}
+
+func Test_IBAN_FillSuccess(t *testing.T) {
+	// given
+	a := &accounts.Attributes{Country: "GB", BankID: "400300", Bic: "NWBKGB22", AccountNumber: "41426819"}
+
+	// when
+	err := Fill(a)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "GB16NWBK40030041426819", a.Iban)
+	require.Empty(t, Check(a))
+}
+
+func Test_IBAN_CheckFailed_Mismatch(t *testing.T) {
+	tests := map[string]struct {
+		attrs *accounts.Attributes
+		field string
+	}{
+		"country":        {&accounts.Attributes{Country: "IE", Iban: "GB16NWBK40030041426819"}, "country"},
+		"bic":            {&accounts.Attributes{Country: "GB", Bic: "BARCGB22", Iban: "GB16NWBK40030041426819"}, "bic"},
+		"bank id":        {&accounts.Attributes{Country: "GB", BankID: "400301", Iban: "GB16NWBK40030041426819"}, "bank_id"},
+		"account number": {&accounts.Attributes{Country: "GB", AccountNumber: "41426818", Iban: "GB16NWBK40030041426819"}, "account_number"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// when
+			err := Check(tt.attrs)
+
+			// then
+			require.IsType(t, &MismatchError{}, err, "Invalid error type")
+			require.Equal(t, tt.field, err.(*MismatchError).Field)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package iban
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// BBAN structures from the SWIFT IBAN registry.
+// Every segment is a length followed by a character class:
+// n - digits, a - upper case letters, c - alphanumeric characters.
+var registry = map[string]string{
+	"AD": "4!n4!n12!c",
+	"AE": "3!n16!n",
+	"AL": "8!n16!c",
+	"AT": "5!n11!n",
+	"AZ": "4!a20!c",
+	"BA": "3!n3!n8!n2!n",
+	"BE": "3!n7!n2!n",
+	"BG": "4!a4!n2!n8!c",
+	"BH": "4!a14!c",
+	"BI": "5!n5!n11!n2!n",
+	"BR": "8!n5!n10!n1!a1!c",
+	"BY": "4!c4!n16!c",
+	"CH": "5!n12!c",
+	"CR": "4!n14!n",
+	"CY": "3!n5!n16!c",
+	"CZ": "4!n6!n10!n",
+	"DE": "8!n10!n",
+	"DJ": "5!n5!n11!n2!n",
+	"DK": "4!n9!n1!n",
+	"DO": "4!c20!n",
+	"EE": "2!n2!n11!n1!n",
+	"EG": "4!n4!n17!n",
+	"ES": "4!n4!n1!n1!n10!n",
+	"FI": "3!n11!n",
+	"FK": "2!a12!n",
+	"FO": "4!n9!n1!n",
+	"FR": "5!n5!n11!c2!n",
+	"GB": "4!a6!n8!n",
+	"GE": "2!a16!n",
+	"GI": "4!a15!c",
+	"GL": "4!n9!n1!n",
+	"GR": "3!n4!n16!c",
+	"GT": "4!c20!c",
+	"HR": "7!n10!n",
+	"HU": "3!n4!n1!n15!n1!n",
+	"IE": "4!a6!n8!n",
+	"IL": "3!n3!n13!n",
+	"IQ": "4!a3!n12!n",
+	"IS": "4!n2!n6!n10!n",
+	"IT": "1!a5!n5!n12!c",
+	"JO": "4!a4!n18!c",
+	"KW": "4!a22!c",
+	"KZ": "3!n13!c",
+	"LB": "4!n20!c",
+	"LC": "4!a24!c",
+	"LI": "5!n12!c",
+	"LT": "5!n11!n",
+	"LU": "3!n13!c",
+	"LV": "4!a13!c",
+	"LY": "3!n3!n15!n",
+	"MC": "5!n5!n11!c2!n",
+	"MD": "2!c18!c",
+	"ME": "3!n13!n2!n",
+	"MK": "3!n10!c2!n",
+	"MN": "4!n12!n",
+	"MR": "5!n5!n11!n2!n",
+	"MT": "4!a5!n18!c",
+	"MU": "4!a2!n2!n12!n3!n3!a",
+	"NI": "4!a20!n",
+	"NL": "4!a10!n",
+	"NO": "4!n6!n1!n",
+	"OM": "3!n16!c",
+	"PK": "4!a16!c",
+	"PL": "8!n16!n",
+	"PS": "4!a21!c",
+	"PT": "4!n4!n11!n2!n",
+	"QA": "4!a21!c",
+	"RO": "4!a16!c",
+	"RS": "3!n13!n2!n",
+	"RU": "9!n5!n15!c",
+	"SA": "2!n18!c",
+	"SC": "4!a2!n2!n16!n3!a",
+	"SD": "2!n12!n",
+	"SE": "3!n16!n1!n",
+	"SI": "5!n8!n2!n",
+	"SK": "4!n6!n10!n",
+	"SM": "1!a5!n5!n12!c",
+	"SO": "4!n3!n12!n",
+	"ST": "4!n4!n11!n2!n",
+	"SV": "4!a20!n",
+	"TL": "3!n14!n2!n",
+	"TN": "2!n3!n13!n2!n",
+	"TR": "5!n1!n16!c",
+	"UA": "6!n19!c",
+	"VA": "3!n15!n",
+	"VG": "4!a16!n",
+	"XK": "4!n10!n2!n",
+}
+
+type segment struct {
+	length int
+	class  byte
+}
+
+type structure struct {
+	segments []segment
+	// length of the whole IBAN including country code and check digits
+	length int
+}
+
+var structures = parseRegistry()
+
+func parseRegistry() map[string]structure {
+	res := make(map[string]structure, len(registry))
+	for country, spec := range registry {
+		s, err := parseStructure(spec)
+		if err != nil {
+			panic(fmt.Sprintf("iban: invalid structure of %s: %v", country, err))
+		}
+		res[country] = s
+	}
+	return res
+}
+
+func parseStructure(spec string) (structure, error) {
+	s := structure{length: 4}
+	for i := 0; i < len(spec); {
+		j := i
+		for j < len(spec) && spec[j] >= '0' && spec[j] <= '9' {
+			j++
+		}
+		if j == i || j+1 >= len(spec) || spec[j] != '!' {
+			return s, fmt.Errorf("unexpected character at %d", j)
+		}
+		n, err := strconv.Atoi(spec[i:j])
+		if err != nil {
+			return s, err
+		}
+		class := spec[j+1]
+		if class != 'n' && class != 'a' && class != 'c' {
+			return s, fmt.Errorf("unknown character class %q", class)
+		}
+		s.segments = append(s.segments, segment{length: n, class: class})
+		s.length += n
+		i = j + 2
+	}
+	return s, nil
+}
+
+func (s structure) match(bban string) bool {
+	pos := 0
+	for _, seg := range s.segments {
+		if pos+seg.length > len(bban) {
+			return false
+		}
+		for _, ch := range []byte(bban[pos : pos+seg.length]) {
+			if !matchClass(seg.class, ch) {
+				return false
+			}
+		}
+		pos += seg.length
+	}
+	return pos == len(bban)
+}
+
+func matchClass(class, ch byte) bool {
+	digit := ch >= '0' && ch <= '9'
+	upper := ch >= 'A' && ch <= 'Z'
+	switch class {
+	case 'n':
+		return digit
+	case 'a':
+		return upper
+	default:
+		return digit || upper || (ch >= 'a' && ch <= 'z')
+	}
+}
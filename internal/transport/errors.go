@@ -0,0 +1,243 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched with errors.Is by errors of failed calls, based on the response status.
+var (
+	ErrInvalidData = errors.New("invalid data")
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// Maximum number of bytes of the response body kept in APIError.
+const MaxErrorBodySize = 64 << 10
+
+// APIError describes a failed call: the request, the response status, headers and body,
+// and error details parsed from the body. It's embedded in errors of resource clients.
+// Errors detected on the client side have it empty.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Method     string `json:"-"`
+	URL        string `json:"-"`
+
+	// Value of X-Request-Id response header, useful for support requests.
+	RequestID string      `json:"-"`
+	Header    http.Header `json:"-"`
+
+	// Raw response body, truncated to MaxErrorBodySize bytes.
+	Body []byte `json:"-"`
+
+	// Form3 error details.
+	Code string `json:"error_code"`
+	Msg  string `json:"error_message"`
+
+	// JSON:API error objects.
+	Errors []ErrorObject `json:"errors"`
+}
+
+// ErrorObject is a JSON:API error object.
+type ErrorObject struct {
+	ID     string       `json:"id,omitempty"`
+	Status string       `json:"status,omitempty"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: status %d", e.Method, e.URL, e.StatusCode)
+	if d := e.Detail(); d != "" {
+		msg += ": " + d
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// Detail returns the error message sent by the server, if any.
+func (e *APIError) Detail() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	details := make([]string, 0, len(e.Errors))
+	for _, o := range e.Errors {
+		switch {
+		case o.Detail != "":
+			details = append(details, o.Detail)
+		case o.Title != "":
+			details = append(details, o.Title)
+		case o.Code != "":
+			details = append(details, o.Code)
+		}
+	}
+	return strings.Join(details, "; ")
+}
+
+// Is matches sentinel errors by the response status.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidData:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+func (e *APIError) apiError() *APIError {
+	return e
+}
+
+// AsAPIError returns APIError embedded in the first error in err's chain that has one.
+func AsAPIError(err error) (*APIError, bool) {
+	var target interface{ apiError() *APIError }
+	if !errors.As(err, &target) {
+		return nil, false
+	}
+	return target.apiError(), true
+}
+
+// newAPIError reads the failed response. Body is read up to MaxErrorBodySize bytes
+// and parsed as Form3 or JSON:API error when possible.
+func newAPIError(req *http.Request, resp *http.Response) APIError {
+	e := APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Header:     resp.Header,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, MaxErrorBodySize))
+	if len(body) > 0 {
+		e.Body = body
+		// bodies that are not JSON, like HTML error pages of proxies, are kept raw only
+		json.Unmarshal(body, &e)
+	}
+	return e
+}
+
+type InvalidDataError struct {
+	APIError
+}
+
+func (e *InvalidDataError) Error() string {
+	if d := e.Detail(); d != "" {
+		return d
+	}
+	return "invalid data"
+}
+
+func (e *InvalidDataError) Is(target error) bool {
+	return target == ErrInvalidData
+}
+
+type HttpStatusError struct {
+	APIError
+}
+
+func (e *HttpStatusError) Error() string {
+	msg := fmt.Sprintf("error code returned: %d", e.StatusCode)
+	if d := e.Detail(); d != "" {
+		msg += ": " + d
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// RateLimitedError is returned when the server rejects the request with 429 status.
+type RateLimitedError struct {
+	APIError
+
+	// How long to wait before sending the request again, from Retry-After header.
+	// Zero if not provided.
+	RetryAfter time.Duration
+
+	// Values of X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+	// Zero if not provided.
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+	}
+	return "rate limited"
+}
+
+func (e *RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// TokenError is returned when an OAuth2 access token couldn't be obtained.
+type TokenError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("could not obtain access token, status code: %d", e.StatusCode)
+	}
+	if e.Description == "" {
+		return fmt.Sprintf("could not obtain access token: %s", e.Code)
+	}
+	return fmt.Sprintf("could not obtain access token: %s: %s", e.Code, e.Description)
+}
+
+// ForeignURLError is returned when a URL, e.g. a pagination link of a response,
+// has another scheme or host than the base URL of the client. Such URLs are not called.
+type ForeignURLError struct {
+	URL     string
+	BaseURL string
+}
+
+func (e *ForeignURLError) Error() string {
+	return fmt.Sprintf("url %s is outside of base url %s", e.URL, e.BaseURL)
+}
+
+// UnexpectedResultError is returned when a middleware short-circuits an operation without
+// an error, but with no result or a result of another type than the operation returns.
+type UnexpectedResultError struct {
+	Service   string
+	Operation string
+
+	// Types of the expected and returned results, e.g. "*accounts.FetchSuccess"
+	Want string
+	Got  string
+}
+
+func (e *UnexpectedResultError) Error() string {
+	return fmt.Sprintf("unexpected result of %s %s: got %s, want %s", e.Service, e.Operation, e.Got, e.Want)
+}
+
+// UnexpectedResult returns UnexpectedResultError for the operation, given the result
+// and a value of the expected type, e.g. a nil *accounts.FetchSuccess.
+func UnexpectedResult(op *Operation, got, want interface{}) error {
+	return &UnexpectedResultError{Service: op.Service, Operation: op.Name, Want: fmt.Sprintf("%T", want), Got: fmt.Sprintf("%T", got)}
+}
@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_APIError_JSONAPIErrors(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		resp := buildResponse(403, `{"errors": [{"status": "403", "code": "forbidden", "title": "Forbidden", "detail": "no access to organisation"}]}`)
+		resp.Header.Set("X-Request-Id", "req-1")
+		return resp, nil
+	})
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	resp, err := c.Do(req, nil)
+	err = CheckStatusCode(resp)
+
+	// then
+	require.IsType(t, &HttpStatusError{}, err, "Invalid error type")
+	e := err.(*HttpStatusError)
+	require.Equal(t, 403, e.StatusCode)
+	require.Equal(t, "req-1", e.RequestID)
+	require.Equal(t, []ErrorObject{{Status: "403", Code: "forbidden", Title: "Forbidden", Detail: "no access to organisation"}}, e.Errors)
+	require.Equal(t, "GET http://form3/v1/organisation/accounts/1: status 403: no access to organisation (request ID req-1)", e.APIError.Error())
+}
+
+func Test_Transport_InvalidDataError_Message(t *testing.T) {
+	for _, tc := range []struct {
+		body string
+		msg  string
+	}{
+		{body: `{"error_message": "bank_id is invalid"}`, msg: "bank_id is invalid"},
+		{body: `{"errors": [{"detail": "bank_id is invalid"}, {"title": "country is required"}]}`, msg: "bank_id is invalid; country is required"},
+		{body: ``, msg: "invalid data"},
+	} {
+		// given
+		ctx := context.Background()
+		c := setUpClient(withResponse(400, tc.body))
+		req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+		// when
+		_, err := c.Do(req, nil)
+
+		// then
+		require.IsType(t, &InvalidDataError{}, err, "Invalid error type")
+		require.Equal(t, tc.msg, err.Error())
+	}
+}
+
+func Test_Transport_APIError_BodyCapped(t *testing.T) {
+	// given
+	ctx := context.Background()
+	body := "<html>" + strings.Repeat("x", MaxErrorBodySize) + "</html>"
+	c := setUpClient(withResponse(502, body))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	apiErr := resp.APIError()
+	require.Equal(t, 502, apiErr.StatusCode)
+	require.Len(t, apiErr.Body, MaxErrorBodySize)
+	require.Empty(t, apiErr.Detail())
+}
+
+func Test_Transport_APIError_Is(t *testing.T) {
+	tests := map[int]error{
+		400: ErrInvalidData,
+		404: ErrNotFound,
+		409: ErrConflict,
+		429: ErrRateLimited,
+	}
+	for status, sentinel := range tests {
+		t.Run(fmt.Sprint(status), func(t *testing.T) {
+			// given
+			err := fmt.Errorf("wrapped: %w", &HttpStatusError{APIError: APIError{StatusCode: status}})
+
+			// then
+			require.True(t, errors.Is(err, sentinel))
+			apiErr, ok := AsAPIError(err)
+			require.True(t, ok)
+			require.Equal(t, status, apiErr.StatusCode)
+		})
+	}
+	require.False(t, errors.Is(&HttpStatusError{APIError: APIError{StatusCode: 500}}, ErrNotFound))
+}
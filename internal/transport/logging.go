@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Logger is the subset of *slog.Logger used by the client, so any structured logger can be adapted.
+// Args are alternating keys and values.
+type Logger interface {
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// Fields of account and payment payloads holding personal data, redacted in logged bodies by default.
+var DefaultRedactedFields = []string{"iban", "account_number", "account_name", "name", "alternative_names", "address", "secondary_identification"}
+
+// Value logged in place of redacted fields.
+const Redacted = "[REDACTED]"
+
+// WithLogger logs every attempt of every request: method, path, status, latency and attempt number.
+// Query strings are not logged, because filters may contain personal data.
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithBodyLogging logs request and response bodies along with attempts, replacing values of given
+// JSON fields, at any depth, with Redacted. DefaultRedactedFields are used when no fields are given.
+// Bodies that are not JSON are not logged, only their size.
+func WithBodyLogging(redact ...string) Option {
+	if len(redact) == 0 {
+		redact = DefaultRedactedFields
+	}
+	fields := make(map[string]bool, len(redact))
+	for _, f := range redact {
+		fields[strings.ToLower(f)] = true
+	}
+	return func(c *Client) {
+		c.redact = fields
+	}
+}
+
+// logAttempt logs the result of an attempt. Response body is read and replaced when bodies are logged.
+func (c *Client) logAttempt(req *http.Request, resp *http.Response, err error, attempt int, latency time.Duration) {
+	if c.logger == nil {
+		return
+	}
+	args := []interface{}{
+		"method", req.Method,
+		"path", req.URL.Path,
+		"attempt", attempt,
+		"latency", latency,
+	}
+	if op := operationOf(req); op != nil {
+		args = append(args, "operation", op.Service+"."+op.Name)
+	}
+	if resp != nil {
+		args = append(args, "status", resp.StatusCode)
+		if id := resp.Header.Get("X-Request-Id"); id != "" {
+			args = append(args, "request_id", id)
+		}
+	}
+	if err != nil {
+		args = append(args, "error", err.Error())
+	}
+	if c.redact != nil {
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				b, _ := ioutil.ReadAll(body)
+				body.Close()
+				args = append(args, "request_body", redact(b, c.redact))
+			}
+		}
+		if resp != nil && resp.Body != nil {
+			b, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+			args = append(args, "response_body", redact(b, c.redact))
+		}
+	}
+
+	ctx := req.Context()
+	if err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		c.logger.WarnContext(ctx, "form3 request failed", args...)
+	} else {
+		c.logger.InfoContext(ctx, "form3 request", args...)
+	}
+}
+
+// redact returns JSON body with values of given fields replaced with Redacted.
+func redact(body []byte, fields map[string]bool) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return fmt.Sprintf("[non-JSON body, %d bytes]", len(body))
+	}
+	b, err := json.Marshal(redactValue(v, fields))
+	if err != nil {
+		return fmt.Sprintf("[body, %d bytes]", len(body))
+	}
+	return string(b)
+}
+
+func redactValue(v interface{}, fields map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, f := range v {
+			if fields[strings.ToLower(k)] {
+				v[k] = Redacted
+			} else {
+				v[k] = redactValue(f, fields)
+			}
+		}
+	case []interface{}:
+		for i, f := range v {
+			v[i] = redactValue(f, fields)
+		}
+	}
+	return v
+}
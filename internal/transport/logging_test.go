@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_LogAttempts(t *testing.T) {
+	// given
+	ctx := context.Background()
+	logger := &recordingLogger{}
+	calls := 0
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		calls++
+		if calls < 2 {
+			return buildResponse(503, ``), nil
+		}
+		return buildResponse(200, `{}`), nil
+	}, WithRetryPolicy(testRetryPolicy), WithLogger(logger))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts?filter%5Biban%5D=GB16NWBK40030041426819", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Len(t, logger.entries, 2)
+	require.Equal(t, "warn", logger.entries[0].level)
+	require.Equal(t, "/v1/organisation/accounts", logger.entries[0].args["path"])
+	require.Equal(t, 503, logger.entries[0].args["status"])
+	require.Equal(t, 1, logger.entries[0].args["attempt"])
+	require.Equal(t, "info", logger.entries[1].level)
+	require.Equal(t, "GET", logger.entries[1].args["method"])
+	require.Equal(t, 200, logger.entries[1].args["status"])
+	require.Equal(t, 2, logger.entries[1].args["attempt"])
+	require.IsType(t, time.Duration(0), logger.entries[1].args["latency"])
+	require.NotContains(t, logger.entries[1].args, "request_body")
+}
+
+func Test_Transport_LogBodies_Redacted(t *testing.T) {
+	// given
+	ctx := context.Background()
+	logger := &recordingLogger{}
+	c := setUpClient(withResponse(201, `{"data": {"id": "1", "attributes": {"country": "GB", "iban": "GB16NWBK40030041426819", "name": ["Jane Doe"]}}}`),
+		WithLogger(logger), WithBodyLogging())
+	body := Envelope{Data: map[string]interface{}{
+		"id":         "1",
+		"version":    0,
+		"attributes": map[string]interface{}{"country": "GB", "account_number": "41426819", "alternative_names": []string{"Jane"}},
+	}}
+	req, _ := c.NewRequest(ctx, "POST", "organisation/accounts", body)
+	var res struct {
+		Data struct {
+			Attributes struct {
+				Iban string `json:"iban"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+
+	// when
+	_, err := c.Do(req, &res)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "GB16NWBK40030041426819", res.Data.Attributes.Iban)
+	require.Len(t, logger.entries, 1)
+	require.JSONEq(t, `{"data": {"id": "1", "version": 0, "attributes": {"country": "GB", "account_number": "[REDACTED]", "alternative_names": "[REDACTED]"}}}`,
+		logger.entries[0].args["request_body"].(string))
+	require.JSONEq(t, `{"data": {"id": "1", "attributes": {"country": "GB", "iban": "[REDACTED]", "name": "[REDACTED]"}}}`,
+		logger.entries[0].args["response_body"].(string))
+}
+
+func Test_Transport_Redact(t *testing.T) {
+	fields := map[string]bool{"secret": true}
+	require.Equal(t, `{"a":[{"Secret":"[REDACTED]"}],"n":1.50}`, redact([]byte(`{"a": [{"Secret": "x"}], "n": 1.50}`), fields))
+	require.Equal(t, "[non-JSON body, 11 bytes]", redact([]byte(`<html>x</h>`), fields))
+	require.Equal(t, "", redact(nil, fields))
+}
+
+type logEntry struct {
+	level string
+	msg   string
+	args  map[string]interface{}
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("info", msg, args)
+}
+
+func (l *recordingLogger) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("warn", msg, args)
+}
+
+func (l *recordingLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := logEntry{level: level, msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		e.args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, e)
+}
@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// Operation describes a call of a resource client, e.g. Create of accounts.
+// Middlewares get it for every call and can read or change it.
+type Operation struct {
+	// Name of the resource service, e.g. "accounts"
+	Service string
+
+	// Name of the operation, e.g. "Create", "Fetch" or "List"
+	Name string
+
+	// ID and version of the resource, when the operation has them.
+	ResourceID string
+	Version    *int64
+
+	// Organisation of the resource, when known before the call.
+	OrganisationID string
+
+	// Number of attempts made to send the request, set when the call completes.
+	// Zero when no request was sent, e.g. when a middleware short-circuited the call.
+	Attempts int
+}
+
+// Invoker performs the operation with given request and returns its typed result,
+// e.g. *accounts.CreateSuccess, and typed error, e.g. *accounts.AccountNotFoundError.
+// The request context is the context of the call.
+type Invoker func(op *Operation, req *http.Request) (interface{}, error)
+
+// Middleware wraps invocations of all operations. It can observe or change the request
+// and the result, or short-circuit the call by returning a result without calling next.
+// Results returned without calling next must have the type the operation returns,
+// otherwise the call fails with UnexpectedResultError.
+type Middleware func(next Invoker) Invoker
+
+// WithMiddleware adds middlewares wrapping all operations. The first one is the outermost.
+func WithMiddleware(m ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, m...)
+	}
+}
+
+type operationKey struct{}
+
+// Invoke calls the operation through the middlewares of the client.
+func (c *Client) Invoke(op *Operation, req *http.Request, call Invoker) (interface{}, error) {
+	inv := call
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		inv = c.middleware[i](inv)
+	}
+	return inv(op, req.WithContext(context.WithValue(req.Context(), operationKey{}, op)))
+}
+
+// InvokeInto calls the operation like Invoke and stores its result in res, a pointer to
+// the typed result, e.g. **accounts.FetchSuccess, so resource clients don't assert result types.
+// When the call succeeds with no result or a result of another type, e.g. returned by a middleware,
+// UnexpectedResultError is returned.
+func (c *Client) InvokeInto(op *Operation, req *http.Request, res interface{}, call Invoker) error {
+	v, err := c.Invoke(op, req, call)
+	out := reflect.ValueOf(res).Elem()
+	got := reflect.ValueOf(v)
+	ok := v != nil && got.Type().AssignableTo(out.Type())
+	if ok {
+		out.Set(got)
+	}
+	if err == nil && (!ok || got.IsNil()) {
+		return UnexpectedResult(op, v, out.Interface())
+	}
+	return err
+}
+
+func operationOf(req *http.Request) *Operation {
+	op, _ := req.Context().Value(operationKey{}).(*Operation)
+	return op
+}
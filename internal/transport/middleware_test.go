@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_InvokeSuccess_MiddlewareOrder(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Invoker) Invoker {
+			return func(op *Operation, req *http.Request) (interface{}, error) {
+				calls = append(calls, name+" before")
+				v, err := next(op, req)
+				calls = append(calls, name+" after")
+				return v, err
+			}
+		}
+	}
+	c := setUpClient(withResponse(200, `{}`), WithMiddleware(trace("outer"), trace("inner")))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	v, err := c.Invoke(&Operation{Name: "Fetch"}, req, func(op *Operation, req *http.Request) (interface{}, error) {
+		calls = append(calls, "call")
+		return "result", nil
+	})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "result", v)
+	require.Equal(t, []string{"outer before", "inner before", "call", "inner after", "outer after"}, calls)
+}
+
+func Test_Transport_InvokeSuccess_Attempts(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		calls++
+		if calls < 2 {
+			return buildResponse(503, ``), nil
+		}
+		return buildResponse(200, `{}`), nil
+	}, WithRetryPolicy(testRetryPolicy))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+	op := &Operation{Name: "Fetch"}
+
+	// when
+	_, err := c.Invoke(op, req, func(op *Operation, req *http.Request) (interface{}, error) {
+		return c.Do(req, nil)
+	})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 2, op.Attempts)
+}
+
+func Test_Transport_InvokeIntoSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(withResponse(200, `{}`))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+	self := "/v1/organisation/accounts/1"
+	want := &Links{Self: &self}
+
+	// when
+	var res *Links
+	err := c.InvokeInto(&Operation{Name: "Fetch"}, req, &res, func(op *Operation, req *http.Request) (interface{}, error) {
+		return want, nil
+	})
+
+	// then
+	require.Empty(t, err)
+	require.Same(t, want, res)
+}
+
+func Test_Transport_InvokeIntoFailed_UnexpectedResult(t *testing.T) {
+	for name, result := range map[string]interface{}{
+		"other type": "result",
+		"nil":        nil,
+		"typed nil":  (*Links)(nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			c := setUpClient(withResponse(200, `{}`))
+			req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+			// when
+			var res *Links
+			err := c.InvokeInto(&Operation{Service: "accounts", Name: "Fetch"}, req, &res, func(op *Operation, req *http.Request) (interface{}, error) {
+				return result, nil
+			})
+
+			// then
+			require.IsType(t, &UnexpectedResultError{}, err, "Invalid error type")
+			require.Equal(t, "*transport.Links", err.(*UnexpectedResultError).Want)
+			require.Nil(t, res)
+		})
+	}
+}
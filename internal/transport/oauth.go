@@ -0,0 +1,188 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tokens are refreshed this long before they expire, so they don't expire in flight.
+// Tokens living shorter than twice as long are refreshed in the middle of their lifetime.
+const tokenExpiryDelta = 30 * time.Second
+
+// Lifetime of tokens issued without expires_in.
+const defaultTokenLifetime = 5 * time.Minute
+
+// Token requests taking longer fail, so a hanging token endpoint doesn't block all callers forever.
+const tokenFetchTimeout = 30 * time.Second
+
+// TokenSource obtains OAuth2 access tokens with the client credentials grant and caches them
+// until shortly before they expire. It's safe for concurrent use: when the token must be
+// refreshed, only one request is sent to the token endpoint and other callers wait for it.
+type TokenSource struct {
+	httpClient *http.Client
+	tokenURL   string
+	clientID   string
+	secret     string
+	now        func() time.Time
+	timeout    time.Duration
+
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time
+	inflight *tokenCall
+}
+
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func NewTokenSource(c *http.Client, tokenURL, clientID, secret string) *TokenSource {
+	return &TokenSource{
+		httpClient: c,
+		tokenURL:   tokenURL,
+		clientID:   clientID,
+		secret:     secret,
+		now:        time.Now,
+		timeout:    tokenFetchTimeout,
+	}
+}
+
+// WithTokenSource authorizes every request with a bearer token from given source.
+// Requests rejected with 401 status are sent once more with a fresh token.
+func WithTokenSource(s *TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = s
+	}
+}
+
+// Token returns a cached token or fetches a new one.
+// Callers waiting for a token fetched by another call share its result.
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	if s.token != "" && s.now().Before(s.expiry) {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+	call := s.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		s.inflight = call
+		// the fetch is detached from the caller, so its cancellation doesn't fail other waiting callers
+		go s.refresh(context.Background(), call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Invalidate drops given token from the cache, e.g. when the server rejected it.
+// Tokens fetched in the meantime are kept.
+func (s *TokenSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+func (s *TokenSource) refresh(ctx context.Context, call *tokenCall) {
+	var token string
+	var err error
+	defer func() {
+		s.mu.Lock()
+		s.inflight = nil
+		s.mu.Unlock()
+		call.token, call.err = token, err
+		close(call.done)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	start := s.now()
+	var lifetime time.Duration
+	token, lifetime, err = s.fetch(ctx)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.token = token
+	s.expiry = start.Add(refreshAfter(lifetime))
+	s.mu.Unlock()
+}
+
+// refreshAfter returns how long a token with given lifetime is used before it's refreshed.
+func refreshAfter(lifetime time.Duration) time.Duration {
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	if lifetime < 2*tokenExpiryDelta {
+		return lifetime / 2
+	}
+	return lifetime - tokenExpiryDelta
+}
+
+func (s *TokenSource) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequest("POST", s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.secret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", 0, fmt.Errorf("could not fetch token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		e := &TokenError{StatusCode: resp.StatusCode}
+		json.NewDecoder(resp.Body).Decode(e)
+		return "", 0, e
+	}
+	var t struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", 0, fmt.Errorf("could not decode token: %w", err)
+	}
+	if t.AccessToken == "" {
+		return "", 0, &TokenError{StatusCode: resp.StatusCode, Code: "invalid_response", Description: "missing access_token"}
+	}
+	if t.TokenType != "" && !strings.EqualFold(t.TokenType, "bearer") {
+		return "", 0, &TokenError{StatusCode: resp.StatusCode, Code: "invalid_response", Description: "unsupported token type " + t.TokenType}
+	}
+	return t.AccessToken, time.Duration(t.ExpiresIn) * time.Second, nil
+}
+
+// authorize sets Authorization header of the request and returns the token used.
+func (c *Client) authorize(req *http.Request) (string, error) {
+	if c.tokens == nil {
+		return "", nil
+	}
+	token, err := c.tokens.Token(req.Context())
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return token, nil
+}
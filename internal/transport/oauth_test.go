@@ -0,0 +1,247 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenSource_TokenSuccess_Cached(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv, fetches := setUpTokenServer(t, 3600, 0)
+	defer srv.Close()
+	s := NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")
+
+	// when
+	first, err1 := s.Token(ctx)
+	second, err2 := s.Token(ctx)
+
+	// then
+	require.Empty(t, err1)
+	require.Empty(t, err2)
+	require.Equal(t, "token-1", first)
+	require.Equal(t, "token-1", second)
+	require.EqualValues(t, 1, atomic.LoadInt32(fetches))
+}
+
+func Test_TokenSource_TokenSuccess_RefreshedBeforeExpiry(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv, fetches := setUpTokenServer(t, 60, 0)
+	defer srv.Close()
+	s := NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.Token(ctx)
+
+	// when
+	now = now.Add(29 * time.Second)
+	cached, _ := s.Token(ctx)
+	now = now.Add(2 * time.Second)
+	refreshed, err := s.Token(ctx)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "token-1", cached)
+	require.Equal(t, "token-2", refreshed)
+	require.EqualValues(t, 2, atomic.LoadInt32(fetches))
+}
+
+func Test_TokenSource_TokenSuccess_ShortOrMissingExpiry(t *testing.T) {
+	for _, tc := range []struct {
+		expiresIn int
+		cachedFor time.Duration
+	}{
+		{expiresIn: 0, cachedFor: defaultTokenLifetime - tokenExpiryDelta},
+		{expiresIn: 10, cachedFor: 5 * time.Second},
+	} {
+		t.Run(fmt.Sprintf("expires_in=%d", tc.expiresIn), func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			srv, fetches := setUpTokenServer(t, tc.expiresIn, 0)
+			defer srv.Close()
+			s := NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")
+			now := time.Now()
+			s.now = func() time.Time { return now }
+			s.Token(ctx)
+
+			// when
+			now = now.Add(tc.cachedFor - time.Second)
+			cached, _ := s.Token(ctx)
+			now = now.Add(2 * time.Second)
+			refreshed, err := s.Token(ctx)
+
+			// then
+			require.Empty(t, err)
+			require.Equal(t, "token-1", cached)
+			require.Equal(t, "token-2", refreshed)
+			require.EqualValues(t, 2, atomic.LoadInt32(fetches))
+		})
+	}
+}
+
+func Test_TokenSource_TokenFailed_HangingEndpoint(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var fetches int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			<-release
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "token-2", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	defer srv.Close()
+	defer close(release)
+	s := NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")
+	s.timeout = 50 * time.Millisecond
+
+	// when
+	_, err := s.Token(ctx)
+	token, err2 := s.Token(ctx)
+
+	// then
+	require.Error(t, err)
+	require.Empty(t, err2)
+	require.Equal(t, "token-2", token)
+}
+
+func Test_TokenSource_TokenSuccess_SingleFlight(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv, fetches := setUpTokenServer(t, 3600, 50*time.Millisecond)
+	defer srv.Close()
+	s := NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")
+
+	// when
+	var wg sync.WaitGroup
+	tokens := make([]string, 20)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = s.Token(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	require.EqualValues(t, 1, atomic.LoadInt32(fetches))
+	for _, token := range tokens {
+		require.Equal(t, "token-1", token)
+	}
+}
+
+func Test_TokenSource_TokenFailed_InvalidClient(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+		w.Write([]byte(`{"error": "invalid_client", "error_description": "bad credentials"}`))
+	}))
+	defer srv.Close()
+	s := NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "wrong")
+
+	// when
+	_, err := s.Token(ctx)
+
+	// then
+	require.IsType(t, &TokenError{}, err, "Invalid error type")
+	require.Equal(t, 401, err.(*TokenError).StatusCode)
+	require.Equal(t, "invalid_client", err.(*TokenError).Code)
+}
+
+func Test_Transport_AuthorizeSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv, _ := setUpTokenServer(t, 3600, 0)
+	defer srv.Close()
+	var auth string
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		auth = req.Header.Get("Authorization")
+		return buildResponse(200, `{}`), nil
+	}, WithTokenSource(NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "Bearer token-1", auth)
+}
+
+func Test_Transport_AuthorizeSuccess_RetriedOnceOnUnauthorized(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv, fetches := setUpTokenServer(t, 3600, 0)
+	defer srv.Close()
+	var auths []string
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		auths = append(auths, req.Header.Get("Authorization"))
+		if req.Header.Get("Authorization") == "Bearer token-1" {
+			return buildResponse(401, ``), nil
+		}
+		return buildResponse(201, `{}`), nil
+	}, WithTokenSource(NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")))
+	req, _ := c.NewRequest(ctx, "POST", "organisation/accounts", Envelope{Data: map[string]string{"id": "1"}})
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 201, resp.StatusCode)
+	require.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, auths)
+	require.EqualValues(t, 2, atomic.LoadInt32(fetches))
+}
+
+func Test_Transport_AuthorizeFailed_UnauthorizedTwice(t *testing.T) {
+	// given
+	ctx := context.Background()
+	srv, _ := setUpTokenServer(t, 3600, 0)
+	defer srv.Close()
+	calls := 0
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return buildResponse(401, ``), nil
+	}, WithTokenSource(NewTokenSource(srv.Client(), srv.URL+"/v1/oauth2/token", "client", "secret")))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 401, resp.StatusCode)
+	require.Equal(t, 2, calls)
+}
+
+// setUpTokenServer starts OAuth2 token endpoint issuing tokens "token-1", "token-2" and so on,
+// valid for expiresIn seconds. Returns the server and the number of issued tokens.
+func setUpTokenServer(t *testing.T, expiresIn int, latency time.Duration) (*httptest.Server, *int32) {
+	var issued int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if r.Method != "POST" || r.URL.Path != "/v1/oauth2/token" || !ok || id != "client" || secret != "secret" ||
+			r.PostFormValue("grant_type") != "client_credentials" {
+			t.Errorf("invalid token request: %s %s", r.Method, r.URL)
+			w.WriteHeader(400)
+			return
+		}
+		time.Sleep(latency)
+		n := atomic.AddInt32(&issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": %d}`, n, expiresIn)
+	}))
+	return srv, &issued
+}
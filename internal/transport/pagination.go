@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"net/url"
+	"strconv"
+)
+
+type Links struct {
+	// Link to this endpoint or resource. Required.
+	Self *string `json:"self"`
+}
+
+type ListLinks struct {
+	Links
+
+	// Link to the first page of a paginated response.
+	First *string `json:"first,omitempty"`
+
+	// Link to the last page of a paginated response.
+	Last *string `json:"last,omitempty"`
+
+	// Link to the next page of a paginated response.
+	Next *string `json:"next,omitempty"`
+
+	// Link to the previous page of a paginated response.
+	Prev *string `json:"prev,omitempty"`
+}
+
+// HasNext reports whether links point to a next page.
+func (l *ListLinks) HasNext() bool {
+	return l != nil && l.Next != nil && *l.Next != ""
+}
+
+// Page selects a page of a paginated list. Zero values are not sent, so the server defaults are used.
+type Page struct {
+	// Number of the page to fetch, starting from 0.
+	Number int
+
+	// Number of resources on a single page.
+	Size int
+}
+
+// Query builds list query with page parameters and non-empty filter[name] parameters.
+func Query(p Page, filters map[string]string) url.Values {
+	q := url.Values{}
+	if p.Number > 0 {
+		q.Set("page[number]", strconv.Itoa(p.Number))
+	}
+	if p.Size > 0 {
+		q.Set("page[size]", strconv.Itoa(p.Size))
+	}
+	for name, value := range filters {
+		if value != "" {
+			q.Set("filter["+name+"]", value)
+		}
+	}
+	return q
+}
+
+// ListPath appends encoded query to given path.
+func ListPath(path string, q url.Values) string {
+	if enc := q.Encode(); enc != "" {
+		return path + "?" + enc
+	}
+	return path
+}
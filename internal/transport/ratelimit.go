@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket shaping outbound requests. It's safe for concurrent use,
+// so one limiter can be shared by all resource clients.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a limiter allowing rps requests per second on average
+// and bursts of up to burst requests.
+func NewLimiter(rps float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request can be sent. It fails without waiting when
+// the request couldn't be sent before the context deadline.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.advance(now)
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		if l.rate <= 0 {
+			l.tokens++
+			l.mu.Unlock()
+			return fmt.Errorf("rate limit of %v requests per second doesn't allow any request", l.rate)
+		}
+		wait = time.Duration(math.Ceil(-l.tokens / l.rate * float64(time.Second)))
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		l.tokens++
+		l.mu.Unlock()
+		return fmt.Errorf("rate limit wait of %s exceeds context deadline: %w", wait, context.DeadlineExceeded)
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if err := Sleep(ctx, wait); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (l *Limiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+		l.last = now
+	}
+}
+
+// WithLimiter makes the client wait for the limiter before every attempt.
+func WithLimiter(l *Limiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// Limits of waiting for rate limits of a single request, see WithRateLimitWait.
+const (
+	maxRateLimitWaits = 5
+	maxRateLimitWait  = 2 * time.Minute
+)
+
+// WithRateLimitWait makes the client wait and resend requests rejected with 429 status,
+// as long as the server tells how long to wait and the wait fits before the context deadline.
+// Requests rejected with 429 were not processed, so every request is resent, regardless of its method.
+// A request is resent this way at most 5 times and waits at most 2 minutes in total,
+// even without a context deadline. Then the retry policy applies to the 429 response.
+func WithRateLimitWait() Option {
+	return func(c *Client) {
+		c.waitOnRateLimit = true
+		c.rateLimitWaits = maxRateLimitWaits
+		c.rateLimitMaxWait = maxRateLimitWait
+	}
+}
+
+// rateLimitWait returns how long to wait before resending the request rejected with 429 status,
+// and false when the request should not be resent. waits and waited tell how many times
+// and how long the request has already waited for rate limits.
+func (c *Client) rateLimitWait(req *http.Request, resp *http.Response, waits int, waited time.Duration) (time.Duration, bool) {
+	if !c.waitOnRateLimit || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if waits >= c.rateLimitWaits {
+		return 0, false
+	}
+	info := parseRateLimit(resp.Header, time.Now())
+	wait := info.RetryAfter
+	if wait == 0 && !info.Reset.IsZero() {
+		wait = time.Until(info.Reset)
+	}
+	if wait <= 0 || waited+wait > c.rateLimitMaxWait {
+		return 0, false
+	}
+	if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return 0, false
+	}
+	return wait, true
+}
+
+type rateLimitInfo struct {
+	RetryAfter time.Duration
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+}
+
+// parseRateLimit parses Retry-After and X-RateLimit-* headers. Retry-After can be given
+// in seconds or as HTTP date. X-RateLimit-Reset can be given as unix time or in seconds from now.
+// Missing or invalid headers leave zero values.
+func parseRateLimit(h http.Header, now time.Time) rateLimitInfo {
+	var info rateLimitInfo
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			info.RetryAfter = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil && t.After(now) {
+			info.RetryAfter = t.Sub(now)
+		}
+	}
+	info.Limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	info.Remaining, _ = strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if v, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil && v > 0 {
+		if v > 1e9 {
+			info.Reset = time.Unix(v, 0)
+		} else {
+			info.Reset = now.Add(time.Duration(v) * time.Second)
+		}
+	}
+	return info
+}
@@ -0,0 +1,174 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_DoFailed_RateLimited(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(withRateLimitedResponse("7"))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.IsType(t, &RateLimitedError{}, err, "Invalid error type")
+	rl := err.(*RateLimitedError)
+	require.Equal(t, 7*time.Second, rl.RetryAfter)
+	require.Equal(t, 100, rl.Limit)
+	require.Equal(t, 0, rl.Remaining)
+	require.Equal(t, time.Unix(1700000000, 0), rl.Reset)
+}
+
+func Test_Transport_RateLimitWaitSuccess(t *testing.T) {
+	// given
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	calls := 0
+	limited := withRateLimitedResponse("1")
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return limited(req)
+		}
+		return buildResponse(201, `{}`), nil
+	}, WithRateLimitWait())
+	req, _ := c.NewRequest(ctx, "POST", "organisation/accounts", Envelope{})
+	start := time.Now()
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 201, resp.StatusCode)
+	require.Equal(t, 2, resp.Attempts)
+	require.True(t, time.Since(start) >= time.Second, "Should wait for Retry-After")
+}
+
+func Test_Transport_RateLimitWaitFailed_BeyondDeadline(t *testing.T) {
+	// given
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	calls := 0
+	limited := withRateLimitedResponse("30")
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return limited(req)
+	}, WithRateLimitWait())
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.IsType(t, &RateLimitedError{}, err, "Invalid error type")
+	require.Equal(t, 1, calls)
+}
+
+func Test_Transport_RateLimitWaitFailed_TooManyWaits(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	limited := withRateLimitedResponse("1")
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return limited(req)
+	}, WithRateLimitWait())
+	c.rateLimitWaits = 2
+	req, _ := c.NewRequest(ctx, "POST", "organisation/accounts", Envelope{})
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.IsType(t, &RateLimitedError{}, err, "Invalid error type")
+	require.Equal(t, 3, calls)
+}
+
+func Test_Transport_RateLimitWaitFailed_TotalWaitExceeded(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	limited := withRateLimitedResponse("1")
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return limited(req)
+	}, WithRateLimitWait())
+	c.rateLimitMaxWait = 1500 * time.Millisecond
+	req, _ := c.NewRequest(ctx, "POST", "organisation/accounts", Envelope{})
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.IsType(t, &RateLimitedError{}, err, "Invalid error type")
+	require.Equal(t, 2, calls)
+}
+
+func Test_Transport_LimiterShapesTraffic(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(withResponse(200, `{}`), WithLimiter(NewLimiter(20, 2)))
+	start := time.Now()
+
+	// when
+	for i := 0; i < 4; i++ {
+		req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+		_, err := c.Do(req, nil)
+		require.Empty(t, err)
+	}
+
+	// then
+	// 2 requests from the burst, 2 more at 20 rps
+	require.True(t, time.Since(start) >= 90*time.Millisecond, "Should wait for tokens, took %s", time.Since(start))
+}
+
+func Test_Transport_LimiterFailed_BeyondDeadline(t *testing.T) {
+	// given
+	l := NewLimiter(1, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Empty(t, l.Wait(ctx))
+
+	// when
+	err := l.Wait(ctx)
+
+	// then
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "Invalid error %v", err)
+}
+
+func Test_Transport_ParseRateLimit(t *testing.T) {
+	// given
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	h := http.Header{}
+	h.Set("Retry-After", now.Add(90*time.Second).Format(http.TimeFormat))
+	h.Set("X-RateLimit-Reset", "30")
+
+	// when
+	info := parseRateLimit(h, now)
+
+	// then
+	require.Equal(t, 90*time.Second, info.RetryAfter)
+	require.Equal(t, now.Add(30*time.Second), info.Reset)
+}
+
+// withRateLimitedResponse builds a RoundTrip function that returns 429 response with given Retry-After header
+func withRateLimitedResponse(retryAfter string) RoundTrip {
+	return func(*http.Request) (*http.Response, error) {
+		resp := buildResponse(429, ``)
+		resp.Header.Set("Retry-After", retryAfter)
+		resp.Header.Set("X-RateLimit-Limit", "100")
+		resp.Header.Set("X-RateLimit-Remaining", "0")
+		resp.Header.Set("X-RateLimit-Reset", "1700000000")
+		return resp, nil
+	}
+}
@@ -0,0 +1,209 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls retries of failed calls.
+// Safe calls (GET) and idempotent calls (DELETE) are always retried.
+// Other calls are retried only when the resource client marks them with AllowRetry.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+
+	// Delay before the first retry. It's doubled on every next retry.
+	BaseDelay time.Duration
+
+	// Upper bound of the delay between attempts.
+	MaxDelay time.Duration
+
+	// Fraction of the delay, from 0 to 1, that is randomized. 0.5 means the delay
+	// is randomly chosen between 50% and 100% of the computed value.
+	Jitter float64
+
+	// Retryable classifies results of an attempt. Either resp or err is nil.
+	// When nil, DefaultRetryable is used.
+	Retryable func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts and jittered delays from 100ms up to 2s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// DefaultRetryable retries transport errors, like connection resets, except context
+// cancellation, and 429, 500, 502, 503 and 504 responses.
+func DefaultRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+type retrySafeKey struct{}
+
+// AllowRetry marks the request as safe to retry even if its method is not idempotent,
+// e.g. because the resource ID is generated on the client side and duplicates are detected.
+func AllowRetry(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), retrySafeKey{}, true))
+}
+
+func canRetry(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "DELETE":
+		return true
+	}
+	safe, _ := req.Context().Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(resp, err)
+	}
+	return DefaultRetryable(resp, err)
+}
+
+// Delay returns the jittered delay before given retry, counted from 1.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	d := p.MaxDelay
+	if retry <= 32 {
+		if exp := p.BaseDelay << uint(retry-1); exp > 0 && (exp < p.MaxDelay || p.MaxDelay <= 0) {
+			d = exp
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+	if p.Jitter > 0 {
+		j := p.Jitter
+		if j > 1 {
+			j = 1
+		}
+		d -= time.Duration(j * randFloat() * float64(d))
+	}
+	return d
+}
+
+// send sends the request, retrying it according to the policy and waiting for rate limits.
+// Attempts resent after waiting for a rate limit, or with a fresh token after 401 response,
+// don't count to the retry policy attempts. Rate limit waits are bounded separately, see WithRateLimitWait.
+// Returns the last response or error and the number of attempts made.
+func (c *Client) send(req *http.Request) (*http.Response, int, error) {
+	policyAttempts, rateLimitWaits := 0, 0
+	var rateLimitWaited time.Duration
+	reauthorized := false
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			var err error
+			if r, err = rewind(req); err != nil {
+				return nil, attempt - 1, err
+			}
+		}
+		if c.limiter != nil {
+			if err := c.limiter.Wait(req.Context()); err != nil {
+				return nil, attempt - 1, err
+			}
+		}
+		token, err := c.authorize(r)
+		if err != nil {
+			return nil, attempt - 1, err
+		}
+		if err := c.sign(r); err != nil {
+			return nil, attempt - 1, err
+		}
+		start := time.Now()
+		resp, err := c.httpClient.Do(r)
+		c.logAttempt(r, resp, err, attempt, time.Since(start))
+
+		var delay time.Duration
+		ok := false
+		if c.tokens != nil && !reauthorized && resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			// the token might have been revoked or expired early, the request wasn't processed
+			c.tokens.Invalidate(token)
+			reauthorized, ok = true, true
+		} else if delay, ok = c.rateLimitWait(req, resp, rateLimitWaits, rateLimitWaited); ok {
+			rateLimitWaits++
+			rateLimitWaited += delay
+		}
+		if !ok {
+			policyAttempts++
+			if policyAttempts >= c.retry.MaxAttempts || !canRetry(req) || !c.retry.retryable(resp, err) {
+				return resp, attempt, err
+			}
+			delay = c.retry.Delay(policyAttempts)
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				if info := parseRateLimit(resp.Header, time.Now()); info.RetryAfter > delay {
+					delay = info.RetryAfter
+				}
+			}
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := Sleep(req.Context(), delay); err != nil {
+			return nil, attempt, err
+		}
+	}
+}
+
+// rewind returns a copy of the request with a fresh body.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// Sleep waits for given duration. Returns the context error when it's done first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var (
+	rndMu sync.Mutex
+	rnd   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randFloat() float64 {
+	rndMu.Lock()
+	defer rndMu.Unlock()
+	return rnd.Float64()
+}
@@ -0,0 +1,160 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Jitter: 0.5}
+
+func Test_Transport_RetrySuccess_AfterServerErrors(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return buildResponse(503, ``), nil
+		}
+		return buildResponse(200, `{}`), nil
+	}, WithRetryPolicy(testRetryPolicy))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, 3, resp.Attempts)
+}
+
+func Test_Transport_RetrySuccess_AfterConnectionError(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	var bodies []string
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if calls == 1 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return buildResponse(201, `{}`), nil
+	}, WithRetryPolicy(testRetryPolicy))
+	req, _ := c.NewRequest(ctx, "POST", "organisation/accounts", Envelope{Data: "account"})
+
+	// when
+	resp, err := c.Do(AllowRetry(req), nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 201, resp.StatusCode)
+	require.Equal(t, 2, resp.Attempts)
+	require.Equal(t, []string{"{\"data\":\"account\"}\n", "{\"data\":\"account\"}\n"}, bodies, "Body should be resent")
+}
+
+func Test_Transport_RetryFailed_AttemptsExhausted(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		calls++
+		return buildResponse(500, ``), nil
+	}, WithRetryPolicy(testRetryPolicy))
+	req, _ := c.NewRequest(ctx, "DELETE", "organisation/accounts/1?version=0", nil)
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, 3, resp.Attempts)
+	require.IsType(t, &HttpStatusError{}, CheckStatusCode(resp), "Invalid error type")
+}
+
+func Test_Transport_Retry_NotSafeRequest(t *testing.T) {
+	for _, method := range []string{"POST", "PATCH"} {
+		t.Run(method, func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			calls := 0
+			c := setUpClient(func(*http.Request) (*http.Response, error) {
+				calls++
+				return buildResponse(503, ``), nil
+			}, WithRetryPolicy(testRetryPolicy))
+			req, _ := c.NewRequest(ctx, method, "organisation/accounts", Envelope{})
+
+			// when
+			resp, err := c.Do(req, nil)
+
+			// then
+			require.Empty(t, err)
+			require.Equal(t, 1, calls)
+			require.Equal(t, 1, resp.Attempts)
+		})
+	}
+}
+
+func Test_Transport_Retry_NotRetryableStatus(t *testing.T) {
+	// given
+	ctx := context.Background()
+	calls := 0
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		calls++
+		return buildResponse(404, ``), nil
+	}, WithRetryPolicy(testRetryPolicy))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func Test_Transport_RetryFailed_ContextCancelled(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	c := setUpClient(func(*http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return buildResponse(503, ``), nil
+	}, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute}))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 1, calls)
+}
+
+func Test_Transport_RetryPolicyDelay(t *testing.T) {
+	// given
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	// then
+	require.Equal(t, 100*time.Millisecond, p.Delay(1))
+	require.Equal(t, 200*time.Millisecond, p.Delay(2))
+	require.Equal(t, 800*time.Millisecond, p.Delay(4))
+	require.Equal(t, time.Second, p.Delay(5))
+	require.Equal(t, time.Second, p.Delay(100))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.Delay(2)
+		require.True(t, d > 100*time.Millisecond && d <= 200*time.Millisecond, "Invalid jittered delay %s", d)
+	}
+}
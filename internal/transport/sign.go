@@ -0,0 +1,27 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestSigner signs outgoing requests, e.g. *httpsig.Signer.
+type RequestSigner interface {
+	Sign(req *http.Request) error
+}
+
+// WithSigner signs every attempt of every request just before it's sent.
+// Date header is refreshed on each attempt, so retried requests carry a current signature.
+func WithSigner(s RequestSigner) Option {
+	return func(c *Client) {
+		c.signer = s
+	}
+}
+
+func (c *Client) sign(req *http.Request) error {
+	if c.signer == nil {
+		return nil
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return c.signer.Sign(req)
+}
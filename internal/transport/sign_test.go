@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_SignEveryAttempt(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var signed []string
+	c := setUpClient(func(req *http.Request) (*http.Response, error) {
+		signed = append(signed, req.Header.Get("Signature"))
+		if len(signed) < 2 {
+			return buildResponse(503, ``), nil
+		}
+		return buildResponse(200, `{}`), nil
+	}, WithRetryPolicy(testRetryPolicy), WithSigner(signerFunc(func(req *http.Request) error {
+		req.Header.Set("Signature", req.Method+" "+req.URL.Path)
+		return nil
+	})))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	resp, err := c.Do(req, nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 2, resp.Attempts)
+	require.Equal(t, []string{"GET /v1/organisation/accounts/1", "GET /v1/organisation/accounts/1"}, signed)
+}
+
+type signerFunc func(req *http.Request) error
+
+func (f signerFunc) Sign(req *http.Request) error {
+	return f(req)
+}
@@ -0,0 +1,162 @@
+// Package transport implements JSON:API calls shared by all Form3 resource clients:
+// request building, headers, envelope encoding and decoding, error mapping and pagination.
+// It takes resources as interface{}, because the module supports go versions without generics.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// JSON:API media type used for both requests and responses
+const MediaType = "application/vnd.api+json"
+
+// Client sends JSON:API requests to Form3 API with given base URL.
+type Client struct {
+	httpClient *http.Client
+	baseURL    url.URL
+	retry      RetryPolicy
+	limiter    *Limiter
+	signer     RequestSigner
+	tokens     *TokenSource
+	middleware []Middleware
+	logger     Logger
+	redact     map[string]bool
+
+	waitOnRateLimit  bool
+	rateLimitWaits   int
+	rateLimitMaxWait time.Duration
+}
+
+type Option func(*Client)
+
+// Configurable is a resource client accepting transport options before it's built.
+// Resource client options are functions of it, so transport options of resource clients
+// can be set only by packages of this module, see Configure.
+type Configurable interface {
+	AddOptions(opts ...Option)
+}
+
+// Configure returns a resource client option adding given transport options.
+// It's converted to the ClientOption type of the resource package.
+func Configure(opts ...Option) func(Configurable) {
+	return func(c Configurable) {
+		c.AddOptions(opts...)
+	}
+}
+
+func New(c *http.Client, baseURL url.URL, opts ...Option) *Client {
+	client := &Client{httpClient: c, baseURL: baseURL}
+	for _, o := range opts {
+		o(client)
+	}
+	return client
+}
+
+// Response is a HTTP response with the number of attempts it took to get it.
+type Response struct {
+	*http.Response
+
+	// Number of attempts made, greater than 1 when the request was retried.
+	Attempts int
+
+	apiErr APIError
+}
+
+// APIError describes the response when its status is not 2xx.
+// Resource clients embed it in their errors.
+func (r *Response) APIError() APIError {
+	return r.apiErr
+}
+
+// Envelope wraps resources sent in JSON:API request bodies.
+type Envelope struct {
+	Data interface{} `json:"data"`
+}
+
+// NewRequest builds a request to given path, resolved against base URL.
+// Absolute paths, like pagination links returned by the API, are resolved against the host.
+// URLs with another scheme or host than the base URL are rejected with ForeignURLError,
+// so credentials and signatures are never sent to hosts named by responses.
+// Non-nil body is encoded as JSON.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != c.baseURL.Scheme || u.Host != c.baseURL.Host {
+		return nil, &ForeignURLError{URL: u.String(), BaseURL: c.baseURL.String()}
+	}
+	var buf io.ReadWriter
+	if body != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", MediaType)
+	}
+	req.Header.Set("Accept", MediaType)
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return req.WithContext(ctx), nil
+}
+
+// Do sends the request and decodes successful response body into v, unless v is nil.
+// The request is retried according to the retry policy of the client.
+// 400 responses are returned as InvalidDataError and 429 responses as RateLimitedError. Other error statuses are returned
+// with nil error, so callers can map them to resource specific errors, embedding Response.APIError, before CheckStatusCode.
+func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
+	httpResp, attempts, err := c.send(req)
+	if op := operationOf(req); op != nil {
+		op.Attempts += attempts
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	resp := &Response{Response: httpResp, Attempts: attempts}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if v != nil {
+			err = json.NewDecoder(resp.Body).Decode(v)
+		}
+		return resp, err
+	}
+
+	resp.apiErr = newAPIError(req, httpResp)
+	switch resp.StatusCode {
+	case 429:
+		info := parseRateLimit(resp.Header, time.Now())
+		return resp, &RateLimitedError{
+			APIError:   resp.apiErr,
+			RetryAfter: info.RetryAfter,
+			Limit:      info.Limit,
+			Remaining:  info.Remaining,
+			Reset:      info.Reset,
+		}
+	case 400:
+		return resp, &InvalidDataError{APIError: resp.apiErr}
+	}
+	return resp, nil
+}
+
+// CheckStatusCode returns HttpStatusError for non 2xx responses.
+func CheckStatusCode(resp *Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		e := &HttpStatusError{APIError: resp.apiErr}
+		e.StatusCode = resp.StatusCode
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_NewRequest(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(nil)
+
+	// when
+	req, err := c.NewRequest(ctx, "POST", "organisation/accounts", Envelope{Data: map[string]string{"id": "1"}})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/organisation/accounts", req.URL.String())
+	require.Equal(t, MediaType, req.Header.Get("Content-Type"))
+	require.Equal(t, MediaType, req.Header.Get("Accept"))
+	require.NotEmpty(t, req.Header.Get("Date"))
+	body, _ := ioutil.ReadAll(req.Body)
+	require.JSONEq(t, `{"data": {"id": "1"}}`, string(body))
+}
+
+func Test_Transport_NewRequest_AbsolutePath(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(nil)
+
+	// when
+	req, err := c.NewRequest(ctx, "GET", "/v1/organisation/accounts?page%5Bnumber%5D=1", nil)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/organisation/accounts?page%5Bnumber%5D=1", req.URL.String())
+	require.Empty(t, req.Header.Get("Content-Type"))
+}
+
+func Test_Transport_DoSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(withResponse(200, `{"data": {"id": "1"}}`))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+	var res struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+
+	// when
+	resp, err := c.Do(req, &res)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, "1", res.Data.ID)
+}
+
+func Test_Transport_DoFailed_InvalidData(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpClient(withResponse(400, `{"error_message": "some error message", "error_code": "code"}`))
+	req, _ := c.NewRequest(ctx, "GET", "organisation/accounts/1", nil)
+
+	// when
+	_, err := c.Do(req, nil)
+
+	// then
+	require.IsType(t, &InvalidDataError{}, err, "Invalid error type")
+	require.Equal(t, "some error message", err.(*InvalidDataError).Msg)
+	require.Equal(t, "code", err.(*InvalidDataError).Code)
+}
+
+func Test_Transport_CheckStatusCode(t *testing.T) {
+	require.Empty(t, CheckStatusCode(&Response{Response: &http.Response{StatusCode: 204}}))
+	err := CheckStatusCode(&Response{Response: &http.Response{StatusCode: 503}})
+	require.IsType(t, &HttpStatusError{}, err, "Invalid error type")
+	require.Equal(t, 503, err.(*HttpStatusError).StatusCode)
+}
+
+func Test_Transport_Query(t *testing.T) {
+	// when
+	q := Query(Page{Number: 2, Size: 10}, map[string]string{"country": "GB", "iban": ""})
+
+	// then
+	require.Equal(t, "organisation/accounts?filter%5Bcountry%5D=GB&page%5Bnumber%5D=2&page%5Bsize%5D=10", ListPath("organisation/accounts", q))
+	require.Equal(t, "organisation/accounts", ListPath("organisation/accounts", Query(Page{}, nil)))
+}
+
+func setUpClient(r RoundTrip, opts ...Option) *Client {
+	u, err := url.Parse("http://form3/v1/")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return New(&http.Client{Transport: r}, *u, opts...)
+}
+
+// withResponse builds a RoundTrip function that returns HTTP response with given statusCode and body
+func withResponse(statusCode int, body string) RoundTrip {
+	return func(*http.Request) (*http.Response, error) {
+		return buildResponse(statusCode, body), nil
+	}
+}
+
+// buildResponse builds HTTP response with given statusCode and body
+func buildResponse(statusCode int, respBody string) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		ContentLength: int64(len(respBody)),
+	}
+}
+
+type RoundTrip func(*http.Request) (*http.Response, error)
+
+func (r RoundTrip) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r(req)
+}
@@ -0,0 +1,165 @@
+// Package otelform3 instruments Form3 client with OpenTelemetry tracing and metrics.
+//
+// It's a separate module, so the client itself doesn't depend on OpenTelemetry.
+// Instrumentation is a form3 middleware: every service operation gets a client span,
+// W3C trace context headers are injected into its requests, and its latency, errors
+// and retries are recorded.
+//
+//	f3, err := form3.NewClient(otelform3.WithInstrumentation())
+package otelform3
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/althink/form3"
+	"github.com/althink/form3/accounts"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name of the instrumentation scope of tracers and meters.
+const ScopeName = "github.com/althink/form3/otelform3"
+
+// Attribute keys of spans and metrics.
+const (
+	HTTPMethodKey     = attribute.Key("http.method")
+	HTTPStatusCodeKey = attribute.Key("http.status_code")
+	ResourceKey       = attribute.Key("form3.resource")
+	OperationKey      = attribute.Key("form3.operation")
+	ResourceIDKey     = attribute.Key("form3.resource_id")
+	AccountIDKey      = attribute.Key("form3.account_id")
+	OrganisationIDKey = attribute.Key("form3.organisation_id")
+	AttemptsKey       = attribute.Key("form3.attempts")
+	ErrorTypeKey      = attribute.Key("error.type")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider. The global one is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider. The global one is used by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagator sets the propagator injecting trace context into requests.
+// W3C trace context is used by default.
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = p
+	}
+}
+
+// WithInstrumentation is a form3 client option adding Middleware.
+func WithInstrumentation(opts ...Option) form3.Option {
+	return form3.WithMiddleware(Middleware(opts...))
+}
+
+// Middleware instruments every operation of form3 services:
+//   - starts a client span named after the service and operation, e.g. "form3 accounts.Create",
+//     with http.method, form3.resource, form3.operation, account and organisation ID attributes,
+//   - injects trace context headers into the request,
+//   - records form3.client.operation.duration histogram, form3.client.operation.errors counter
+//     by error type and form3.client.retries counter.
+func Middleware(opts ...Option) form3.Middleware {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	tracer := cfg.tracerProvider.Tracer(ScopeName)
+	meter := cfg.meterProvider.Meter(ScopeName)
+
+	duration, err := meter.Float64Histogram("form3.client.operation.duration",
+		metric.WithDescription("Duration of form3 service operations, including retries"),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	errorCount, err := meter.Int64Counter("form3.client.operation.errors",
+		metric.WithDescription("Number of failed form3 service operations"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	retries, err := meter.Int64Counter("form3.client.retries",
+		metric.WithDescription("Number of resent requests of form3 service operations"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next form3.Invoker) form3.Invoker {
+		return func(op *form3.Operation, req *http.Request) (interface{}, error) {
+			attrs := operationAttributes(op, req)
+			ctx, span := tracer.Start(req.Context(), fmt.Sprintf("form3 %s.%s", op.Service, op.Name),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+
+			req = req.WithContext(ctx)
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			start := time.Now()
+			v, err := next(op, req)
+			elapsed := time.Since(start)
+
+			metricAttrs := metric.WithAttributes(attrs[:3]...)
+			span.SetAttributes(AttemptsKey.Int(op.Attempts))
+			if op.Attempts > 1 {
+				retries.Add(ctx, int64(op.Attempts-1), metricAttrs)
+			}
+			duration.Record(ctx, elapsed.Seconds(), metricAttrs)
+			if err != nil {
+				errType := fmt.Sprintf("%T", err)
+				if apiErr, ok := accounts.AsAPIError(err); ok && apiErr.StatusCode != 0 {
+					span.SetAttributes(HTTPStatusCodeKey.Int(apiErr.StatusCode))
+				}
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				errorCount.Add(ctx, 1, metric.WithAttributes(append(attrs[:3:3], ErrorTypeKey.String(errType))...))
+			}
+			return v, err
+		}
+	}
+}
+
+// operationAttributes returns attributes of the operation. The first three,
+// with low cardinality, are used in metrics as well.
+func operationAttributes(op *form3.Operation, req *http.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		HTTPMethodKey.String(req.Method),
+		ResourceKey.String(op.Service),
+		OperationKey.String(op.Name),
+	}
+	if op.ResourceID != "" {
+		attrs = append(attrs, ResourceIDKey.String(op.ResourceID))
+		if op.Service == accounts.Type {
+			attrs = append(attrs, AccountIDKey.String(op.ResourceID))
+		}
+	}
+	if op.OrganisationID != "" {
+		attrs = append(attrs, OrganisationIDKey.String(op.OrganisationID))
+	}
+	return attrs
+}
@@ -0,0 +1,149 @@
+package otelform3
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/althink/form3"
+	"github.com/althink/form3/accounts"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const accountID = "eb89cce1-3b1f-4b37-967f-23354c5ad61e"
+
+func Test_Otel_FetchSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	spans, reader, opts := setUpTelemetry()
+	var traceparent string
+	f3 := setUpClient(t, func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		return buildResponse(200, `{"data": {"id": "`+accountID+`"}}`), nil
+	}, opts...)
+
+	// when
+	_, err := f3.Accounts.Fetch(ctx, accountID)
+
+	// then
+	require.Empty(t, err)
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	require.Equal(t, "form3 accounts.Fetch", span.Name())
+	require.Contains(t, span.Attributes(), HTTPMethodKey.String("GET"))
+	require.Contains(t, span.Attributes(), ResourceKey.String("accounts"))
+	require.Contains(t, span.Attributes(), OperationKey.String("Fetch"))
+	require.Contains(t, span.Attributes(), AccountIDKey.String(accountID))
+	require.Contains(t, span.Attributes(), AttemptsKey.Int(1))
+	require.Equal(t, "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", traceparent)
+
+	metrics := collect(t, reader)
+	require.EqualValues(t, 1, histogramCount(t, metrics, "form3.client.operation.duration"))
+	require.Nil(t, find(metrics, "form3.client.operation.errors"))
+}
+
+func Test_Otel_CreateFailed_Retried(t *testing.T) {
+	// given
+	ctx := context.Background()
+	spans, reader, opts := setUpTelemetry()
+	f3 := setUpClient(t, func(req *http.Request) (*http.Response, error) {
+		return buildResponse(500, `{"error_message": "unavailable"}`), nil
+	}, append(opts, form3.WithRetryPolicy(form3.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))...)
+	account := accounts.New(accountID, "org-1", &accounts.Attributes{Country: "GB"})
+
+	// when
+	_, err := f3.Accounts.Create(ctx, account)
+
+	// then
+	require.IsType(t, &accounts.HttpStatusError{}, err, "Invalid error type")
+	span := spans.Ended()[0]
+	require.Equal(t, "form3 accounts.Create", span.Name())
+	require.Equal(t, codes.Error, span.Status().Code)
+	require.Contains(t, span.Attributes(), OrganisationIDKey.String("org-1"))
+	require.Contains(t, span.Attributes(), HTTPStatusCodeKey.Int(500))
+	require.Contains(t, span.Attributes(), AttemptsKey.Int(3))
+
+	metrics := collect(t, reader)
+	errors := find(metrics, "form3.client.operation.errors").Data.(metricdata.Sum[int64]).DataPoints
+	require.Len(t, errors, 1)
+	require.EqualValues(t, 1, errors[0].Value)
+	errType, _ := errors[0].Attributes.Value(ErrorTypeKey)
+	require.Equal(t, "*transport.HttpStatusError", errType.AsString())
+	retries := find(metrics, "form3.client.retries").Data.(metricdata.Sum[int64]).DataPoints
+	require.EqualValues(t, 2, retries[0].Value)
+	op, _ := retries[0].Attributes.Value(OperationKey)
+	require.Equal(t, "Create", op.AsString())
+	_, hasID := retries[0].Attributes.Value(AccountIDKey)
+	require.False(t, hasID, "metrics must not have high cardinality attributes")
+}
+
+func setUpTelemetry() (*tracetest.SpanRecorder, *sdkmetric.ManualReader, []form3.Option) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	opt := WithInstrumentation(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	return spans, reader, []form3.Option{opt}
+}
+
+func setUpClient(t *testing.T, r RoundTrip, opts ...form3.Option) *form3.Form3 {
+	u, _ := url.Parse("http://form3/v1/")
+	f3, err := form3.NewClient(append([]form3.Option{
+		form3.WithBaseURL(*u),
+		form3.WithHTTPClient(&http.Client{Transport: r}),
+	}, opts...)...)
+	require.Empty(t, err)
+	return f3
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) []metricdata.Metrics {
+	var rm metricdata.ResourceMetrics
+	require.Empty(t, reader.Collect(context.Background(), &rm))
+	var metrics []metricdata.Metrics
+	for _, sm := range rm.ScopeMetrics {
+		metrics = append(metrics, sm.Metrics...)
+	}
+	return metrics
+}
+
+func find(metrics []metricdata.Metrics, name string) *metricdata.Metrics {
+	for i := range metrics {
+		if metrics[i].Name == name {
+			return &metrics[i]
+		}
+	}
+	return nil
+}
+
+func histogramCount(t *testing.T, metrics []metricdata.Metrics, name string) uint64 {
+	m := find(metrics, name)
+	require.NotNil(t, m, "missing metric %s", name)
+	return m.Data.(metricdata.Histogram[float64]).DataPoints[0].Count
+}
+
+// buildResponse builds HTTP response with given statusCode and body
+func buildResponse(statusCode int, respBody string) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		ContentLength: int64(len(respBody)),
+	}
+}
+
+type RoundTrip func(*http.Request) (*http.Response, error)
+
+func (r RoundTrip) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r(req)
+}
@@ -0,0 +1,334 @@
+package payments
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/althink/form3/accounts"
+	"github.com/althink/form3/internal/transport"
+	"github.com/google/uuid"
+)
+
+// Payments service interface
+// See https://api-docs.form3.tech/api.html#transaction-payments for
+// more information about operations and fields.
+//
+// Errors returned for server responses embed APIError with the response details
+// and can be matched with errors.Is against ErrNotFound, ErrConflict, ErrRateLimited and ErrInvalidData.
+type Service interface {
+
+	// Create creates a payment. It's not sent to the scheme until a submission is created.
+	// Creates are never retried, so a payment is never created twice by the client.
+	//
+	// When data format is invalid returns InvalidDataError
+	// When payment with given id already exists returns PaymentAlreadyExistsError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	Create(ctx context.Context, payment *Data) (*CreateSuccess, error)
+
+	// Fetch returns a single payment using the payment ID.
+	//
+	// When payment with given id does not exist returns PaymentNotFoundError
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	Fetch(ctx context.Context, id string) (*FetchSuccess, error)
+
+	// List returns a single page of payments matching given options.
+	// Links of the returned page can be followed with ListNext.
+	//
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	List(ctx context.Context, opts ListOptions) (*ListSuccess, error)
+
+	// ListNext returns the page pointed by the Next link of given page.
+	// When there is no next page returns nil page and nil error.
+	//
+	// When the Next link has another scheme or host than the base URL returns ForeignURLError
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	ListNext(ctx context.Context, page *ListSuccess) (*ListSuccess, error)
+
+	// CreateSubmission submits the payment with given id to its scheme.
+	// The status of the submission changes as the payment is processed, see FetchSubmission.
+	// Creates are never retried, so a payment is never submitted twice by the client.
+	//
+	// When payment with given id does not exist returns PaymentNotFoundError
+	// When submission with given id already exists returns SubmissionAlreadyExistsError
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	CreateSubmission(ctx context.Context, paymentID string, submission *Submission) (*SubmissionSuccess, error)
+
+	// FetchSubmission returns a submission of the payment with its current status.
+	//
+	// When payment or submission with given id does not exist returns SubmissionNotFoundError
+	// When data format is invalid returns InvalidDataError
+	// When rate limited returns RateLimitedError
+	// When other http error status returned returns HttpStatusError
+	FetchSubmission(ctx context.Context, paymentID, submissionID string) (*SubmissionSuccess, error)
+}
+
+type CreateSuccess struct {
+	Data  *Data  `json:"data"`
+	Links *Links `json:"links"`
+}
+
+type FetchSuccess struct {
+	Data  *Data  `json:"data"`
+	Links *Links `json:"links"`
+}
+
+type ListSuccess struct {
+	Data  []*Data    `json:"data"`
+	Links *ListLinks `json:"links"`
+}
+
+// HasNext reports whether there is a next page to fetch.
+func (l *ListSuccess) HasNext() bool {
+	return l != nil && l.Links.HasNext()
+}
+
+type SubmissionSuccess struct {
+	Data  *Submission `json:"data"`
+	Links *Links      `json:"links"`
+}
+
+// Options of the payments List operation.
+// Zero values are not sent, so the server defaults are used.
+type ListOptions struct {
+	// Number of the page to fetch, starting from 0.
+	PageNumber int
+
+	// Number of payments on a single page.
+	PageSize int
+
+	Filter ListFilter
+}
+
+// Filters of the payments List operation. Only payments matching all
+// non-empty fields are returned.
+type ListFilter struct {
+	Currency                 string
+	Amount                   string
+	Scheme                   Scheme
+	Reference                string
+	EndToEndReference        string
+	DebtorAccountNumber      string
+	DebtorBankID             string
+	BeneficiaryAccountNumber string
+	BeneficiaryBankID        string
+}
+
+func (o ListOptions) query() url.Values {
+	return transport.Query(transport.Page{Number: o.PageNumber, Size: o.PageSize}, map[string]string{
+		"currency":                         o.Filter.Currency,
+		"amount":                           o.Filter.Amount,
+		"payment_scheme":                   string(o.Filter.Scheme),
+		"reference":                        o.Filter.Reference,
+		"end_to_end_reference":             o.Filter.EndToEndReference,
+		"debtor_party.account_number":      o.Filter.DebtorAccountNumber,
+		"debtor_party.bank_id":             o.Filter.DebtorBankID,
+		"beneficiary_party.account_number": o.Filter.BeneficiaryAccountNumber,
+		"beneficiary_party.bank_id":        o.Filter.BeneficiaryBankID,
+	})
+}
+
+// Create new payment object
+func New(id string, orgID string, attributes *Attributes) *Data {
+	return &Data{
+		ID:             id,
+		OrganisationID: orgID,
+		Type:           Type,
+		Attributes:     attributes,
+	}
+}
+
+// Create new payment object with random ID
+func NewWithGenID(orgID string, attributes *Attributes) *Data {
+	return New(uuid.New().String(), orgID, attributes)
+}
+
+// Create new submission object
+func NewSubmission(id string, orgID string) *Submission {
+	return &Submission{
+		ID:             id,
+		OrganisationID: orgID,
+		Type:           SubmissionType,
+	}
+}
+
+// Create new submission object with random ID
+func NewSubmissionWithGenID(orgID string) *Submission {
+	return NewSubmission(uuid.New().String(), orgID)
+}
+
+// PartyFromAccount builds a debtor or beneficiary party identified by the account:
+// its account number and bank ID, or its IBAN when it has no account number,
+// with the account holder name and the country of the account.
+func PartyFromAccount(account *accounts.Data) *Party {
+	p := &Party{}
+	if account == nil || account.Attributes == nil {
+		return p
+	}
+	attrs := account.Attributes
+	if attrs.AccountNumber != "" {
+		p.AccountNumber = attrs.AccountNumber
+		p.AccountNumberCode = AccountNumberCodeBBAN
+	} else if attrs.Iban != "" {
+		p.AccountNumber = attrs.Iban
+		p.AccountNumberCode = AccountNumberCodeIBAN
+	}
+	if attrs.BankID != "" || attrs.BankIDCode != "" || attrs.Bic != "" {
+		p.AccountWith = &AccountWith{BankID: attrs.BankID, BankIDCode: attrs.BankIDCode, Bic: attrs.Bic}
+	}
+	if len(attrs.Name) > 0 {
+		p.AccountName = strings.Join(attrs.Name, " ")
+		p.Name = p.AccountName
+	}
+	p.Country = attrs.Country
+	return p
+}
+
+const Type = "payments"
+
+const SubmissionType = "payment_submissions"
+
+// Represents a payment in the form3 transaction section.
+type Data struct {
+	// The specific attributes for each type of resource
+	// Required: true
+	Attributes *Attributes `json:"attributes,omitempty"`
+
+	// The unique ID of the resource in UUID 4 format. It identifies the resource within the system.
+	// Required: true
+	ID string `json:"id,omitempty"`
+
+	// The organisation ID of the organisation by which this resource has been created
+	// Required: true
+	OrganisationID string `json:"organisation_id,omitempty"`
+
+	// The type of resource: "payments"
+	Type string `json:"type,omitempty"`
+
+	// A counter indicating how many times this resource has been modified.
+	// Minimum: 0
+	Version *int64 `json:"version,omitempty"`
+}
+
+type Attributes struct {
+
+	// Amount of money moved between the debtor and beneficiary, a decimal value with up to 2 decimal places, e.g. "100.21"
+	// Required: true
+	Amount string `json:"amount,omitempty"`
+
+	// ISO 4217 code of the currency of the amount, e.g. 'GBP', 'EUR'
+	// Required: true
+	Currency string `json:"currency,omitempty"`
+
+	// The party sending the money
+	// Required: true
+	DebtorParty *Party `json:"debtor_party,omitempty"`
+
+	// The party receiving the money
+	// Required: true
+	BeneficiaryParty *Party `json:"beneficiary_party,omitempty"`
+
+	// Payment scheme used to send the payment
+	// Required: true
+	Scheme Scheme `json:"payment_scheme,omitempty"`
+
+	// Scheme specific payment type, e.g. ImmediatePayment for FPS
+	SchemePaymentType SchemePaymentType `json:"scheme_payment_type,omitempty"`
+
+	// Payment reference for the beneficiary, e.g. an invoice number. Maximum length 18 characters for FPS.
+	Reference string `json:"reference,omitempty"`
+
+	// Unique identifier of the payment assigned by the debtor, passed unchanged through the whole payment chain
+	EndToEndReference string `json:"end_to_end_reference,omitempty"`
+
+	// Numeric reference field, see scheme specific documentation for usage
+	NumericReference string `json:"numeric_reference,omitempty"`
+
+	// Date on which the payment is to be debited from the debtor account, formatted as YYYY-MM-DD
+	ProcessingDate string `json:"processing_date,omitempty"`
+
+	// Scheme specific unique identifier of the payment, assigned by Form3
+	UniqueSchemeID string `json:"unique_scheme_id,omitempty"`
+}
+
+// Debtor or beneficiary party of a payment, see PartyFromAccount.
+type Party struct {
+	// Account number of the party. Type of the number is given by AccountNumberCode.
+	AccountNumber string `json:"account_number,omitempty"`
+
+	// Type of the account number: BBAN or IBAN
+	AccountNumberCode AccountNumberCode `json:"account_number_code,omitempty"`
+
+	// Name of the account holder
+	AccountName string `json:"account_name,omitempty"`
+
+	// Bank holding the account of the party
+	AccountWith *AccountWith `json:"account_with,omitempty"`
+
+	// Name of the party
+	Name string `json:"name,omitempty"`
+
+	// Address of the party, up to three lines
+	Address []string `json:"address,omitempty"`
+
+	// ISO 3166-1 code of the country of the party, e.g. 'GB', 'FR'
+	Country string `json:"country,omitempty"`
+}
+
+// Bank holding the account of a party.
+type AccountWith struct {
+	// Local country bank identifier, e.g. a UK sort code
+	BankID string `json:"bank_id,omitempty"`
+
+	// Type of the bank identifier, e.g. GBDSC for UK sort codes
+	BankIDCode accounts.BankIDCode `json:"bank_id_code,omitempty"`
+
+	// SWIFT BIC in either 8 or 11 character format e.g. 'NWBKGB22'
+	Bic string `json:"bic,omitempty"`
+}
+
+// Represents a submission of a payment to its scheme.
+type Submission struct {
+	Attributes *SubmissionAttributes `json:"attributes,omitempty"`
+
+	// The unique ID of the submission in UUID 4 format.
+	// Required: true
+	ID string `json:"id,omitempty"`
+
+	// The organisation ID of the organisation by which this resource has been created
+	// Required: true
+	OrganisationID string `json:"organisation_id,omitempty"`
+
+	// The type of resource: "payment_submissions"
+	Type string `json:"type,omitempty"`
+
+	// A counter indicating how many times this resource has been modified.
+	Version *int64 `json:"version,omitempty"`
+}
+
+// Attributes of a submission, set by Form3 as the payment is processed.
+type SubmissionAttributes struct {
+	Status SubmissionStatus `json:"status,omitempty"`
+
+	// Description of the status, e.g. the reason of a failed delivery
+	StatusReason string `json:"status_reason,omitempty"`
+
+	// Time of the submission to the scheme, in RFC 3339 format
+	SubmissionDatetime string `json:"submission_datetime,omitempty"`
+
+	// Status code returned by the scheme
+	SchemeStatusCode string `json:"scheme_status_code,omitempty"`
+}
+
+type Links = transport.Links
+
+type ListLinks = transport.ListLinks
@@ -0,0 +1,174 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/althink/form3/internal/transport"
+)
+
+const paymentsBasePath = "transaction/payments"
+
+// ClientOption configures the payments client. Transport settings, like retries
+// or middlewares, are set with form3.NewClient options.
+type ClientOption func(transport.Configurable)
+
+func NewClient(c *http.Client, baseURL url.URL, opts ...ClientOption) Service {
+	client := &httpClient{}
+	for _, o := range opts {
+		o(client)
+	}
+	client.t = transport.New(c, baseURL, client.transportOpts...)
+	return client
+}
+
+func (c *httpClient) AddOptions(opts ...transport.Option) {
+	c.transportOpts = append(c.transportOpts, opts...)
+}
+
+type httpClient struct {
+	t             *transport.Client
+	transportOpts []transport.Option
+}
+
+func (c *httpClient) Create(ctx context.Context, payment *Data) (*CreateSuccess, error) {
+	req, err := c.t.NewRequest(ctx, "POST", paymentsBasePath, transport.Envelope{Data: payment})
+	if err != nil {
+		return nil, err
+	}
+	op := c.operation("Create", payment.ID)
+	op.OrganisationID = payment.OrganisationID
+	var res *CreateSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return c.create(req, payment)
+	})
+	return res, err
+}
+
+func (c *httpClient) create(req *http.Request, payment *Data) (*CreateSuccess, error) {
+	var res CreateSuccess
+	resp, err := c.t.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 409 {
+		return nil, &PaymentAlreadyExistsError{APIError: resp.APIError(), ID: payment.ID}
+	}
+	return &res, transport.CheckStatusCode(resp)
+}
+
+func (c *httpClient) Fetch(ctx context.Context, id string) (*FetchSuccess, error) {
+	url := fmt.Sprintf("%s/%s", paymentsBasePath, id)
+	req, err := c.t.NewRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	op := c.operation("Fetch", id)
+	var res *FetchSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return c.fetch(req, id)
+	})
+	return res, err
+}
+
+func (c *httpClient) fetch(req *http.Request, id string) (*FetchSuccess, error) {
+	var res FetchSuccess
+	resp, err := c.t.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 404 {
+		return nil, &PaymentNotFoundError{APIError: resp.APIError(), ID: id}
+	}
+	return &res, transport.CheckStatusCode(resp)
+}
+
+func (c *httpClient) List(ctx context.Context, opts ListOptions) (*ListSuccess, error) {
+	return c.list(ctx, "List", transport.ListPath(paymentsBasePath, opts.query()))
+}
+
+func (c *httpClient) ListNext(ctx context.Context, page *ListSuccess) (*ListSuccess, error) {
+	if !page.HasNext() {
+		return nil, nil
+	}
+	return c.list(ctx, "ListNext", *page.Links.Next)
+}
+
+func (c *httpClient) list(ctx context.Context, name, url string) (*ListSuccess, error) {
+	req, err := c.t.NewRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	op := c.operation(name, "")
+	var res *ListSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		var res ListSuccess
+		resp, err := c.t.Do(req, &res)
+		if err != nil {
+			return nil, err
+		}
+		return &res, transport.CheckStatusCode(resp)
+	})
+	return res, err
+}
+
+func (c *httpClient) CreateSubmission(ctx context.Context, paymentID string, submission *Submission) (*SubmissionSuccess, error) {
+	url := fmt.Sprintf("%s/%s/submissions", paymentsBasePath, paymentID)
+	req, err := c.t.NewRequest(ctx, "POST", url, transport.Envelope{Data: submission})
+	if err != nil {
+		return nil, err
+	}
+	op := c.operation("CreateSubmission", paymentID)
+	op.OrganisationID = submission.OrganisationID
+	var res *SubmissionSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		return c.createSubmission(req, paymentID, submission)
+	})
+	return res, err
+}
+
+func (c *httpClient) createSubmission(req *http.Request, paymentID string, submission *Submission) (*SubmissionSuccess, error) {
+	var res SubmissionSuccess
+	resp, err := c.t.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == 404 {
+		return nil, &PaymentNotFoundError{APIError: resp.APIError(), ID: paymentID}
+	} else if resp.StatusCode == 409 {
+		return nil, &SubmissionAlreadyExistsError{APIError: resp.APIError(), PaymentID: paymentID, ID: submission.ID}
+	}
+	return &res, transport.CheckStatusCode(resp)
+}
+
+func (c *httpClient) FetchSubmission(ctx context.Context, paymentID, submissionID string) (*SubmissionSuccess, error) {
+	url := fmt.Sprintf("%s/%s/submissions/%s", paymentsBasePath, paymentID, submissionID)
+	req, err := c.t.NewRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	op := c.operation("FetchSubmission", paymentID)
+	var res *SubmissionSuccess
+	err = c.t.InvokeInto(op, req, &res, func(op *transport.Operation, req *http.Request) (interface{}, error) {
+		var res SubmissionSuccess
+		resp, err := c.t.Do(req, &res)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == 404 {
+			return nil, &SubmissionNotFoundError{APIError: resp.APIError(), PaymentID: paymentID, ID: submissionID}
+		}
+		return &res, transport.CheckStatusCode(resp)
+	})
+	return res, err
+}
+
+func (c *httpClient) operation(name, id string) *transport.Operation {
+	return &transport.Operation{Service: Type, Name: name, ResourceID: id}
+}
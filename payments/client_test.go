@@ -0,0 +1,370 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/althink/form3/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+const paymentBody = `{
+						"data": {
+							"type": "payments",
+							"id": "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43",
+							"version": 0,
+							"organisation_id": "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb",
+							"attributes": {
+								"amount": "100.21",
+								"currency": "GBP",
+								"payment_scheme": "FPS",
+								"scheme_payment_type": "ImmediatePayment",
+								"reference": "Payment for piano lessons",
+								"end_to_end_reference": "Wil piano Jan",
+								"numeric_reference": "1002001",
+								"processing_date": "2017-01-18",
+								"debtor_party": {
+									"account_number": "41426819",
+									"account_number_code": "BBAN",
+									"account_name": "Samantha Holder",
+									"account_with": {"bank_id": "400300", "bank_id_code": "GBDSC"},
+									"name": "Samantha Holder",
+									"country": "GB"
+								},
+								"beneficiary_party": {
+									"account_number": "GB11NWBK40030041426819",
+									"account_number_code": "IBAN",
+									"account_name": "Wilfred Owens",
+									"address": ["1 The Beneficiary Localtown SE2"]
+								}
+							}
+						},
+						"links": {
+							"self": "/v1/transaction/payments/4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+						}
+					}`
+
+func Test_Payments_CreateSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var reqURL string
+	var reqBody map[string]interface{}
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURL = req.URL.String()
+		json.NewDecoder(req.Body).Decode(&reqBody)
+		return buildResponse(201, paymentBody), nil
+	})
+	debtor := accounts.New("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb", &accounts.Attributes{
+		Country:       "GB",
+		AccountNumber: "41426819",
+		BankID:        "400300",
+		BankIDCode:    accounts.BankIDCodeGB,
+		Name:          []string{"Samantha Holder"},
+	})
+	payment := New("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb", &Attributes{
+		Amount:           "100.21",
+		Currency:         "GBP",
+		Scheme:           SchemeFPS,
+		DebtorParty:      PartyFromAccount(debtor),
+		BeneficiaryParty: &Party{AccountNumber: "GB11NWBK40030041426819", AccountNumberCode: AccountNumberCodeIBAN},
+	})
+
+	// when
+	created, err := c.Create(ctx, payment)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/transaction/payments", reqURL, "Invalid request URL")
+	data := reqBody["data"].(map[string]interface{})
+	require.Equal(t, "payments", data["type"])
+	require.Equal(t, map[string]interface{}{
+		"account_number":      "41426819",
+		"account_number_code": "BBAN",
+		"account_name":        "Samantha Holder",
+		"account_with":        map[string]interface{}{"bank_id": "400300", "bank_id_code": "GBDSC"},
+		"name":                "Samantha Holder",
+		"country":             "GB",
+	}, data["attributes"].(map[string]interface{})["debtor_party"], "Invalid debtor party")
+
+	require.Equal(t, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", created.Data.ID, "Invalid ID")
+	require.Equal(t, int64(0), *created.Data.Version, "Invalid Version")
+	attrs := created.Data.Attributes
+	require.Equal(t, "100.21", attrs.Amount, "Invalid Amount")
+	require.Equal(t, "GBP", attrs.Currency, "Invalid Currency")
+	require.Equal(t, SchemeFPS, attrs.Scheme, "Invalid Scheme")
+	require.Equal(t, SchemePaymentTypeImmediatePayment, attrs.SchemePaymentType, "Invalid SchemePaymentType")
+	require.Equal(t, "Payment for piano lessons", attrs.Reference, "Invalid Reference")
+	require.Equal(t, "Wil piano Jan", attrs.EndToEndReference, "Invalid EndToEndReference")
+	require.Equal(t, "2017-01-18", attrs.ProcessingDate, "Invalid ProcessingDate")
+	require.Equal(t, PartyFromAccount(debtor), attrs.DebtorParty, "Invalid DebtorParty")
+	require.Equal(t, AccountNumberCodeIBAN, attrs.BeneficiaryParty.AccountNumberCode, "Invalid BeneficiaryParty")
+	require.Equal(t, []string{"1 The Beneficiary Localtown SE2"}, attrs.BeneficiaryParty.Address, "Invalid BeneficiaryParty")
+}
+
+func Test_Payments_CreateFailed_AlreadyExists(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(409, `{"error_message": "payment already exists"}`))
+
+	// when
+	_, err := c.Create(ctx, &Data{ID: "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"})
+
+	// then
+	require.IsType(t, &PaymentAlreadyExistsError{}, err, "Invalid error type")
+	require.Equal(t, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", err.(*PaymentAlreadyExistsError).ID)
+	require.Equal(t, "payment already exists", err.(*PaymentAlreadyExistsError).Msg)
+	require.True(t, errors.Is(err, ErrConflict))
+}
+
+func Test_Payments_CreateFailed_InvalidData(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(400, `{"error_message": "amount in body is required"}`))
+
+	// when
+	_, err := c.Create(ctx, NewWithGenID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb", &Attributes{}))
+
+	// then
+	require.IsType(t, &InvalidDataError{}, err, "Invalid error type")
+	require.Equal(t, "amount in body is required", err.(*InvalidDataError).Msg)
+}
+
+func Test_Payments_FetchSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	var reqURL string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURL = req.URL.String()
+		return buildResponse(200, paymentBody), nil
+	})
+
+	// when
+	fetched, err := c.Fetch(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/transaction/payments/4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", reqURL, "Invalid request URL")
+	require.Equal(t, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", fetched.Data.ID, "Invalid ID")
+	require.Equal(t, "/v1/transaction/payments/4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", *fetched.Links.Self, "Invalid Self link")
+}
+
+func Test_Payments_FetchFailed_UnknownPayment(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(404, ``))
+
+	// when
+	_, err := c.Fetch(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+
+	// then
+	require.IsType(t, &PaymentNotFoundError{}, err, "Invalid error type")
+	require.Equal(t, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", err.(*PaymentNotFoundError).ID)
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func Test_Payments_FetchFailed_500Error(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(500, ``))
+
+	// when
+	_, err := c.Fetch(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+
+	// then
+	require.IsType(t, &HttpStatusError{}, err, "Invalid error type")
+	require.Equal(t, 500, err.(*HttpStatusError).StatusCode)
+}
+
+func Test_Payments_ListSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseBody := `{
+						"data": [
+							{"type": "payments", "id": "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", "attributes": {"amount": "100.21", "currency": "GBP"}}
+						],
+						"links": {
+							"self": "/v1/transaction/payments?page%5Bnumber%5D=0&page%5Bsize%5D=1",
+							"next": "/v1/transaction/payments?page%5Bnumber%5D=1&page%5Bsize%5D=1"
+						}
+					}`
+	var reqURLs []string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURLs = append(reqURLs, req.URL.String())
+		if len(reqURLs) > 1 {
+			return buildResponse(200, `{"data": [], "links": {}}`), nil
+		}
+		return buildResponse(200, responseBody), nil
+	})
+
+	// when
+	list, err := c.List(ctx, ListOptions{PageSize: 1, Filter: ListFilter{Currency: "GBP", Scheme: SchemeFPS}})
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/transaction/payments?filter%5Bcurrency%5D=GBP&filter%5Bpayment_scheme%5D=FPS&page%5Bsize%5D=1", reqURLs[0], "Invalid request URL")
+	require.Len(t, list.Data, 1)
+	require.Equal(t, "100.21", list.Data[0].Attributes.Amount, "Invalid Amount")
+	require.True(t, list.HasNext())
+
+	// when
+	next, err := c.ListNext(ctx, list)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/transaction/payments?page%5Bnumber%5D=1&page%5Bsize%5D=1", reqURLs[1], "Invalid request URL")
+	require.Empty(t, next.Data)
+	require.False(t, next.HasNext())
+}
+
+func Test_Payments_CreateSubmissionSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseBody := `{
+						"data": {
+							"type": "payment_submissions",
+							"id": "8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c",
+							"version": 0,
+							"organisation_id": "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb",
+							"attributes": {"status": "accepted", "submission_datetime": "2017-01-18T10:51:03.224Z"}
+						}
+					}`
+	var reqURL, reqMethod string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURL, reqMethod = req.URL.String(), req.Method
+		return buildResponse(201, responseBody), nil
+	})
+
+	// when
+	created, err := c.CreateSubmission(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43",
+		NewSubmission("8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c", "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb"))
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "POST", reqMethod)
+	require.Equal(t, "http://form3/v1/transaction/payments/4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43/submissions", reqURL, "Invalid request URL")
+	require.Equal(t, "8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c", created.Data.ID, "Invalid ID")
+	require.Equal(t, SubmissionType, created.Data.Type, "Invalid Type")
+	require.Equal(t, SubmissionStatusAccepted, created.Data.Attributes.Status, "Invalid Status")
+	require.False(t, created.Data.Attributes.Status.IsFinal())
+}
+
+func Test_Payments_CreateSubmissionFailed(t *testing.T) {
+	// given
+	ctx := context.Background()
+	submission := NewSubmissionWithGenID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb")
+
+	// when
+	_, notFound := setUpMockClient(withResponse(404, ``)).CreateSubmission(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", submission)
+	_, conflict := setUpMockClient(withResponse(409, ``)).CreateSubmission(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", submission)
+
+	// then
+	require.IsType(t, &PaymentNotFoundError{}, notFound, "Invalid error type")
+	require.Equal(t, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", notFound.(*PaymentNotFoundError).ID)
+	require.IsType(t, &SubmissionAlreadyExistsError{}, conflict, "Invalid error type")
+	require.Equal(t, submission.ID, conflict.(*SubmissionAlreadyExistsError).ID)
+	require.True(t, errors.Is(conflict, ErrConflict))
+}
+
+func Test_Payments_FetchSubmissionSuccess(t *testing.T) {
+	// given
+	ctx := context.Background()
+	responseBody := `{
+						"data": {
+							"type": "payment_submissions",
+							"id": "8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c",
+							"version": 2,
+							"attributes": {"status": "delivery_failed", "status_reason": "Beneficiary account closed", "scheme_status_code": "1114"}
+						}
+					}`
+	var reqURL string
+	c := setUpMockClient(func(req *http.Request) (*http.Response, error) {
+		reqURL = req.URL.String()
+		return buildResponse(200, responseBody), nil
+	})
+
+	// when
+	fetched, err := c.FetchSubmission(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", "8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c")
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, "http://form3/v1/transaction/payments/4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43/submissions/8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c", reqURL, "Invalid request URL")
+	require.Equal(t, SubmissionStatusDeliveryFailed, fetched.Data.Attributes.Status, "Invalid Status")
+	require.True(t, fetched.Data.Attributes.Status.IsFinal())
+	require.Equal(t, "Beneficiary account closed", fetched.Data.Attributes.StatusReason, "Invalid StatusReason")
+	require.Equal(t, "1114", fetched.Data.Attributes.SchemeStatusCode, "Invalid SchemeStatusCode")
+}
+
+func Test_Payments_FetchSubmissionFailed_Unknown(t *testing.T) {
+	// given
+	ctx := context.Background()
+	c := setUpMockClient(withResponse(404, ``))
+
+	// when
+	_, err := c.FetchSubmission(ctx, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", "8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c")
+
+	// then
+	require.IsType(t, &SubmissionNotFoundError{}, err, "Invalid error type")
+	require.Equal(t, "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43", err.(*SubmissionNotFoundError).PaymentID)
+	require.Equal(t, "8ebd4b63-4b12-4a4e-9b94-4f1e1ee5d07c", err.(*SubmissionNotFoundError).ID)
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func Test_Payments_PartyFromAccount_Iban(t *testing.T) {
+	// given
+	account := accounts.NewWithGenID("743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb", &accounts.Attributes{
+		Country: "DE",
+		Iban:    "DE89370400440532013000",
+		Bic:     "COBADEFFXXX",
+		Name:    []string{"Hans", "Muller"},
+	})
+
+	// when
+	party := PartyFromAccount(account)
+
+	// then
+	require.Equal(t, &Party{
+		AccountNumber:     "DE89370400440532013000",
+		AccountNumberCode: AccountNumberCodeIBAN,
+		AccountName:       "Hans Muller",
+		AccountWith:       &AccountWith{Bic: "COBADEFFXXX"},
+		Name:              "Hans Muller",
+		Country:           "DE",
+	}, party)
+}
+
+func setUpMockClient(r RoundTrip, opts ...ClientOption) Service {
+	u, err := url.Parse("http://form3/v1/")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return NewClient(&http.Client{Transport: r}, *u, opts...)
+}
+
+// withResponse builds a RoundTrip function that returns HTTP response with given statusCode and body
+func withResponse(statusCode int, body string) RoundTrip {
+	return func(*http.Request) (*http.Response, error) {
+		return buildResponse(statusCode, body), nil
+	}
+}
+
+// buildResponse builds HTTP response with given statusCode and body
+func buildResponse(statusCode int, respBody string) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(respBody)),
+		ContentLength: int64(len(respBody)),
+	}
+}
+
+type RoundTrip func(*http.Request) (*http.Response, error)
+
+func (r RoundTrip) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r(req)
+}
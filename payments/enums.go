@@ -0,0 +1,82 @@
+package payments
+
+// Enumerated attribute values.
+//
+// Values unknown to this library are kept as they are, so the client keeps working
+// when Form3 adds new values.
+
+// Payment scheme, see Attributes.Scheme
+type Scheme string
+
+const (
+	SchemeFPS         Scheme = "FPS"
+	SchemeBacs        Scheme = "Bacs"
+	SchemeSEPA        Scheme = "SEPACT"
+	SchemeSEPAInstant Scheme = "SEPAINSTANT"
+)
+
+func (s Scheme) IsKnown() bool {
+	switch s {
+	case SchemeFPS, SchemeBacs, SchemeSEPA, SchemeSEPAInstant:
+		return true
+	}
+	return false
+}
+
+// Scheme specific payment type, see Attributes.SchemePaymentType
+type SchemePaymentType string
+
+const (
+	SchemePaymentTypeImmediatePayment    SchemePaymentType = "ImmediatePayment"
+	SchemePaymentTypeForwardDatedPayment SchemePaymentType = "ForwardDatedPayment"
+	SchemePaymentTypeStandingOrder       SchemePaymentType = "StandingOrder"
+	SchemePaymentTypeDirectCredit        SchemePaymentType = "DirectCredit"
+)
+
+func (t SchemePaymentType) IsKnown() bool {
+	switch t {
+	case SchemePaymentTypeImmediatePayment, SchemePaymentTypeForwardDatedPayment,
+		SchemePaymentTypeStandingOrder, SchemePaymentTypeDirectCredit:
+		return true
+	}
+	return false
+}
+
+// Type of the account number of a party, see Party.AccountNumberCode
+type AccountNumberCode string
+
+const (
+	AccountNumberCodeBBAN AccountNumberCode = "BBAN"
+	AccountNumberCodeIBAN AccountNumberCode = "IBAN"
+)
+
+func (c AccountNumberCode) IsKnown() bool {
+	return c == AccountNumberCodeBBAN || c == AccountNumberCodeIBAN
+}
+
+// Status of a payment submission, see SubmissionAttributes.Status
+type SubmissionStatus string
+
+const (
+	SubmissionStatusValidationPending SubmissionStatus = "validation_pending"
+	SubmissionStatusAccepted          SubmissionStatus = "accepted"
+	SubmissionStatusQueuedForDelivery SubmissionStatus = "queued_for_delivery"
+	SubmissionStatusReleasedToGateway SubmissionStatus = "released_to_gateway"
+	SubmissionStatusDeliveryConfirmed SubmissionStatus = "delivery_confirmed"
+	SubmissionStatusDeliveryFailed    SubmissionStatus = "delivery_failed"
+)
+
+func (s SubmissionStatus) IsKnown() bool {
+	switch s {
+	case SubmissionStatusValidationPending, SubmissionStatusAccepted, SubmissionStatusQueuedForDelivery,
+		SubmissionStatusReleasedToGateway, SubmissionStatusDeliveryConfirmed, SubmissionStatusDeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// IsFinal reports whether the submission won't change its status anymore,
+// i.e. the delivery of the payment has been confirmed or has failed.
+func (s SubmissionStatus) IsFinal() bool {
+	return s == SubmissionStatusDeliveryConfirmed || s == SubmissionStatusDeliveryFailed
+}
@@ -0,0 +1,96 @@
+package payments
+
+import (
+	"fmt"
+
+	"github.com/althink/form3/internal/transport"
+)
+
+// APIError describes a failed call: request method and URL, response status, headers, request ID,
+// raw body (up to 64KiB) and error details parsed from it. It's embedded in all errors returned
+// for server responses.
+type APIError = transport.APIError
+
+// ErrorObject is a JSON:API error object, see APIError.Errors
+type ErrorObject = transport.ErrorObject
+
+// Sentinel errors, matched with errors.Is by errors of failed calls.
+var (
+	ErrInvalidData = transport.ErrInvalidData
+	ErrNotFound    = transport.ErrNotFound
+	ErrConflict    = transport.ErrConflict
+	ErrRateLimited = transport.ErrRateLimited
+)
+
+// AsAPIError returns APIError embedded in the first error in err's chain that has one.
+func AsAPIError(err error) (*APIError, bool) {
+	return transport.AsAPIError(err)
+}
+
+type InvalidDataError = transport.InvalidDataError
+
+type HttpStatusError = transport.HttpStatusError
+
+type RateLimitedError = transport.RateLimitedError
+
+type UnexpectedResultError = transport.UnexpectedResultError
+
+type ForeignURLError = transport.ForeignURLError
+
+type PaymentNotFoundError struct {
+	APIError
+
+	ID string
+}
+
+func (e *PaymentNotFoundError) Error() string {
+	return fmt.Sprintf("payment not found: %s", e.ID)
+}
+
+func (e *PaymentNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+type PaymentAlreadyExistsError struct {
+	APIError
+
+	ID string
+}
+
+func (e *PaymentAlreadyExistsError) Error() string {
+	return fmt.Sprintf("payment already exists: %s", e.ID)
+}
+
+func (e *PaymentAlreadyExistsError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+type SubmissionNotFoundError struct {
+	APIError
+
+	PaymentID string
+	ID        string
+}
+
+func (e *SubmissionNotFoundError) Error() string {
+	return fmt.Sprintf("submission not found: %s of payment %s", e.ID, e.PaymentID)
+}
+
+func (e *SubmissionNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+type SubmissionAlreadyExistsError struct {
+	APIError
+
+	PaymentID string
+	ID        string
+}
+
+func (e *SubmissionAlreadyExistsError) Error() string {
+	return fmt.Sprintf("submission already exists: %s of payment %s", e.ID, e.PaymentID)
+}
+
+func (e *SubmissionAlreadyExistsError) Is(target error) bool {
+	return target == ErrConflict
+}
@@ -0,0 +1,140 @@
+// Package referencemask implements reference masks used by Form3 to validate the reference
+// field of inbound payments, see Attributes.ReferenceMask of the accounts package.
+//
+// Mask syntax:
+//
+//	?   matches any character
+//	#   matches any numeric character (0-9)
+//	$   matches any alphanumeric character (a-z, A-Z, 0-9)
+//	\   escapes the next character, so it's matched literally, e.g. \# matches '#'
+//
+// All other characters are literals. Dash and space characters are ignored,
+// both in the mask and in the matched reference. Masks have up to 35 characters.
+package referencemask
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maximum length of a mask
+const MaxLength = 35
+
+type kind int
+
+const (
+	anyChar kind = iota
+	digit
+	alnum
+	literal
+)
+
+type token struct {
+	kind kind
+	lit  rune
+}
+
+// Mask is a compiled reference mask. It's safe for concurrent use.
+type Mask struct {
+	raw    string
+	tokens []token
+}
+
+// SyntaxError describes an invalid mask. Pos is the index of the offending character,
+// counted in characters (runes) from 0.
+type SyntaxError struct {
+	Mask string
+	Pos  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid reference mask %q at position %d: %s", e.Mask, e.Pos, e.Msg)
+}
+
+// Parse compiles the mask.
+func Parse(mask string) (*Mask, error) {
+	chars := []rune(mask)
+	if len(chars) > MaxLength {
+		return nil, &SyntaxError{Mask: mask, Pos: MaxLength, Msg: fmt.Sprintf("mask longer than %d characters", MaxLength)}
+	}
+
+	m := &Mask{raw: mask}
+	for i := 0; i < len(chars); i++ {
+		ch := chars[i]
+		switch ch {
+		case '-', ' ':
+			continue
+		case '?':
+			m.tokens = append(m.tokens, token{kind: anyChar})
+		case '#':
+			m.tokens = append(m.tokens, token{kind: digit})
+		case '$':
+			m.tokens = append(m.tokens, token{kind: alnum})
+		case '\\':
+			if i+1 == len(chars) {
+				return nil, &SyntaxError{Mask: mask, Pos: i, Msg: "escape character at the end of mask"}
+			}
+			i++
+			if chars[i] == '-' || chars[i] == ' ' {
+				return nil, &SyntaxError{Mask: mask, Pos: i, Msg: fmt.Sprintf("escaped %q is ignored in references and can't be matched", chars[i])}
+			}
+			m.tokens = append(m.tokens, token{kind: literal, lit: chars[i]})
+		default:
+			m.tokens = append(m.tokens, token{kind: literal, lit: ch})
+		}
+	}
+	if len(m.tokens) == 0 {
+		return nil, &SyntaxError{Mask: mask, Pos: 0, Msg: "mask has no characters to match"}
+	}
+	return m, nil
+}
+
+// MustParse is like Parse but panics if the mask is invalid.
+func MustParse(mask string) *Mask {
+	m, err := Parse(mask)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Match reports whether the reference matches the mask.
+// Dash and space characters of the reference are ignored.
+func (m *Mask) Match(reference string) bool {
+	chars := []rune(strings.NewReplacer("-", "", " ", "").Replace(reference))
+	if len(chars) != len(m.tokens) {
+		return false
+	}
+	for i, t := range m.tokens {
+		ch := chars[i]
+		switch t.kind {
+		case digit:
+			if !isDigit(ch) {
+				return false
+			}
+		case alnum:
+			if !isDigit(ch) && !isLetter(ch) {
+				return false
+			}
+		case literal:
+			if ch != t.lit {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the source of the mask.
+func (m *Mask) String() string {
+	return m.raw
+}
+
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isLetter(ch rune) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
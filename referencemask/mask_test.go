@@ -0,0 +1,77 @@
+package referencemask
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReferenceMask_Match(t *testing.T) {
+	tests := []struct {
+		mask      string
+		reference string
+		match     bool
+	}{
+		{"############", "123456789012", true},
+		{"############", "1234-5678 9012", true},
+		{"############", "12345678901", false},
+		{"############", "1234567890123", false},
+		{"############", "12345678901A", false},
+		{"ACC-####", "ACC1234", true},
+		{"ACC-####", "acc1234", false},
+		{"$$$$", "aZ09", true},
+		{"$$$$", "aZ0_", false},
+		{"??", "_.", true},
+		{`\#\?\$\\##`, `#?$\12`, true},
+		{`\#\?\$\\##`, `1?$\12`, false},
+		{"INV ####", "INV-0001", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mask+"/"+tt.reference, func(t *testing.T) {
+			// given
+			m, err := Parse(tt.mask)
+			require.Empty(t, err)
+
+			// when
+			match := m.Match(tt.reference)
+
+			// then
+			require.Equal(t, tt.match, match)
+		})
+	}
+}
+
+func Test_ReferenceMask_ParseFailed(t *testing.T) {
+	tests := map[string]int{
+		`###\`:                  3,
+		strings.Repeat("#", 36): 35,
+		"":                      0,
+		" - ":                   0,
+		`##\-`:                  3,
+	}
+	for mask, pos := range tests {
+		t.Run(mask, func(t *testing.T) {
+			// when
+			_, err := Parse(mask)
+
+			// then
+			require.IsType(t, &SyntaxError{}, err, "Invalid error type")
+			require.Equal(t, pos, err.(*SyntaxError).Pos)
+			require.Equal(t, mask, err.(*SyntaxError).Mask)
+		})
+	}
+}
+
+func Test_ReferenceMask_MaxLength(t *testing.T) {
+	// given
+	mask := strings.Repeat("#", MaxLength)
+
+	// when
+	m, err := Parse(mask)
+
+	// then
+	require.Empty(t, err)
+	require.Equal(t, mask, m.String())
+	require.True(t, m.Match(strings.Repeat("7", MaxLength)))
+}